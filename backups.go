@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+)
+
+// backupTimeFormat is used in the names of the backup archives, so that they sort chronologically
+const backupTimeFormat = "2006-01-02_15-04-05"
+
+// defaultBackupKeep is the number of backups kept for each version when OWLCMS_LAUNCHER_BACKUPKEEP is not set
+const defaultBackupKeep = 20
+
+// backupContents are the version subdirectories that hold the competition data and the local customizations
+var backupContents = []string{"database", "local"}
+
+// backup is a zip archive of the data of a version
+type backup struct {
+	Version string
+	Path    string
+	Time    time.Time
+	Reason  string // what triggered it: stop, update, import...
+	Size    int64
+}
+
+// getBackupDir returns the folder holding the backups, one subfolder per version
+func getBackupDir() string {
+	return GetSetting("OWLCMS_LAUNCHER_BACKUPDIR", filepath.Join(owlcmsInstallDir, "backups"))
+}
+
+// getBackupKeep returns how many backups of each kind are kept for each version; 0 keeps them all
+func getBackupKeep() int {
+	keep, err := strconv.Atoi(GetSetting("OWLCMS_LAUNCHER_BACKUPKEEP", strconv.Itoa(defaultBackupKeep)))
+	if err != nil || keep < 0 {
+		log.Printf("Invalid OWLCMS_LAUNCHER_BACKUPKEEP, keeping %d backups\n", defaultBackupKeep)
+		return defaultBackupKeep
+	}
+	return keep
+}
+
+// backupVersion zips the database and local folders of a version, or of a workspace given its key, into the
+// backup folder, then applies the retention policy. It returns the path of the archive, or "" if there is no
+// data yet.
+func backupVersion(version, reason string) (string, error) {
+	versionDir := getDataDir(version)
+	hasData := false
+	for _, subdir := range backupContents {
+		if _, err := os.Stat(filepath.Join(versionDir, subdir)); err == nil {
+			hasData = true
+		}
+	}
+	if !hasData {
+		log.Printf("Nothing to back up for %s\n", version)
+		return "", nil
+	}
+
+	dir := filepath.Join(getBackupDir(), version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating backup folder %s: %w", dir, err)
+	}
+	zipPath := filepath.Join(dir, fmt.Sprintf("%s_%s.zip", time.Now().Format(backupTimeFormat), reason))
+	if err := downloadUtils.CreateZip(zipPath, versionDir, backupContents...); err != nil {
+		return "", fmt.Errorf("backing up %s: %w", version, err)
+	}
+	log.Printf("Backed up %s to %s\n", version, zipPath)
+
+	if err := pruneBackups(getBackupDir(), version, getBackupKeep()); err != nil {
+		log.Printf("Failed to remove old backups of %s: %v\n", version, err)
+	}
+	return zipPath, nil
+}
+
+// listBackups returns the backups of a version, most recent first
+func listBackups(version string) []backup {
+	return listBackupsIn(getBackupDir(), version)
+}
+
+// listBackupsIn returns the backups of a version found under a backup folder, most recent first
+func listBackupsIn(root, version string) []backup {
+	dir := filepath.Join(root, version)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".zip") || len(name) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, name[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		reason := strings.TrimPrefix(strings.TrimSuffix(name[len(backupTimeFormat):], ".zip"), "_")
+		backups = append(backups, backup{Version: version, Path: filepath.Join(dir, name), Time: t, Reason: reason, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups
+}
+
+// pruneBackups removes the oldest backups of a version beyond the number to keep. Each trigger is counted
+// separately, so that frequent scheduled backups do not push out the ones made before an update.
+func pruneBackups(root, version string, keep int) error {
+	if keep == 0 {
+		return nil
+	}
+	count := map[string]int{}
+	for _, b := range listBackupsIn(root, version) {
+		count[b.Reason]++
+		if count[b.Reason] <= keep {
+			continue
+		}
+		log.Printf("Removing old backup %s\n", b.Path)
+		if err := os.Remove(b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreBackup replaces the database and local folders of a version with those of a backup.
+// The backup is extracted aside so that a damaged archive leaves the version untouched, then the current
+// data is backed up. Extracting first matters: backing up prunes old backups, possibly the one being restored.
+// The server must not be running the version.
+func restoreBackup(b backup, version string) error {
+	versionDir := getDataDir(version)
+	stagingDir := filepath.Join(versionDir, ".restore")
+	os.RemoveAll(stagingDir)
+	if err := downloadUtils.ExtractZipFile(b.Path, stagingDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("extracting %s: %w", filepath.Base(b.Path), err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if _, err := backupVersion(version, "restore"); err != nil {
+		return fmt.Errorf("the current data could not be backed up: %w", err)
+	}
+
+	for _, subdir := range backupContents {
+		restored := filepath.Join(stagingDir, subdir)
+		if _, err := os.Stat(restored); os.IsNotExist(err) {
+			continue
+		}
+		current := filepath.Join(versionDir, subdir)
+		previous := current + ".old"
+		os.RemoveAll(previous)
+		if err := os.Rename(current, previous); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("moving %s aside: %w", subdir, err)
+		}
+		if err := os.Rename(restored, current); err != nil {
+			os.Rename(previous, current)
+			return fmt.Errorf("restoring %s: %w", subdir, err)
+		}
+		os.RemoveAll(previous)
+	}
+	log.Printf("Restored %s into %s\n", b.Path, version)
+	return nil
+}
+
+// stopServerAndWait stops the running server and waits until it has exited, so that its files can be replaced
+func stopServerAndWait(w fyne.Window) error {
+	done := processDone
+	version := currentVersion
+	stopProcess(currentProcess, currentVersion, stopButton, downloadContainer, versionContainer, statusLabel, w)
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(60 * time.Second):
+		return fmt.Errorf("OWLCMS %s did not stop", version)
+	}
+}
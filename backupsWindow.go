@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showBackupsWindow lists the backups of each installed version, with restore and export actions
+func showBackupsWindow() {
+	win := fyne.CurrentApp().NewWindow("Backups")
+	versions := getAllDataKeys()
+	rows := container.NewVBox()
+	versionSelect := widget.NewSelect(versions, nil)
+
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		version := versionSelect.Selected
+		backups := listBackups(version)
+		if len(backups) == 0 {
+			rows.Add(widget.NewLabel(fmt.Sprintf("No backups of %s in %s", version, getBackupDir())))
+		}
+		for _, b := range backups {
+			b := b
+			description := fmt.Sprintf("%s    %s    %s", b.Time.Format("2006-01-02 15:04:05"), b.Reason, downloadUtils.FormatSize(b.Size))
+			buttons := container.NewHBox(
+				widget.NewButton("Restore", func() {
+					confirmRestore(b, b.Version, win, refresh)
+				}),
+				widget.NewButton("Restore Into...", func() {
+					restoreIntoOtherVersion(b, win, refresh)
+				}),
+				widget.NewButton("Export", func() {
+					exportBackup(b, win)
+				}),
+			)
+			rows.Add(container.NewBorder(nil, nil, nil, buttons, widget.NewLabel(description)))
+		}
+		rows.Refresh()
+	}
+	versionSelect.OnChanged = func(string) {
+		refresh()
+	}
+
+	backupButton := widget.NewButton("Back Up Now", func() {
+		version := versionSelect.Selected
+		if runningDataKey() == version {
+			dialog.ShowError(fmt.Errorf("stop OWLCMS %s before backing it up, the database is in use", version), win)
+			return
+		}
+		if path, err := backupVersion(version, "manual"); err != nil {
+			dialog.ShowError(err, win)
+		} else if path == "" {
+			dialog.ShowInformation("Backups", fmt.Sprintf("OWLCMS %s has no data to back up yet.", version), win)
+		}
+		refresh()
+	})
+	folderButton := widget.NewButton("Open Folder", func() {
+		dir := filepath.Join(getBackupDir(), versionSelect.Selected)
+		if _, err := os.Stat(dir); err != nil {
+			dir = getBackupDir()
+		}
+		if err := openFileExplorer(dir); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to open %s: %w", dir, err), win)
+		}
+	})
+
+	if len(versions) == 0 {
+		win.SetContent(container.NewPadded(widget.NewLabel("No version or workspace is installed.")))
+	} else {
+		if currentProcess != nil {
+			versionSelect.SetSelected(runningDataKey())
+		} else {
+			versionSelect.SetSelected(versions[0])
+		}
+		top := container.NewBorder(nil, nil, widget.NewLabel("Version"), container.NewHBox(backupButton, folderButton), versionSelect)
+		win.SetContent(container.NewBorder(top, nil, nil, nil, container.NewVScroll(rows)))
+	}
+	win.Resize(fyne.NewSize(750, 400))
+	win.Show()
+}
+
+// confirmRestore restores a backup into a version once confirmed, stopping the server first if it runs that version
+func confirmRestore(b backup, version string, w fyne.Window, refresh func()) {
+	message := fmt.Sprintf("Replace the database and local files of OWLCMS %s\nwith the backup of %s taken on %s?\nThe current data will be backed up first.",
+		version, b.Version, b.Time.Format("2006-01-02 15:04:05"))
+	running := runningDataKey() == version
+	if running {
+		message += fmt.Sprintf("\nOWLCMS %s is running and will be stopped.", version)
+	}
+	dialog.ShowConfirm("Confirm Restore", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		progressDialog := dialog.NewCustom("Restoring", "Please wait...",
+			widget.NewLabel(fmt.Sprintf("Restoring %s into %s...", filepath.Base(b.Path), version)), w)
+		progressDialog.Show()
+		go func() {
+			if running {
+				if err := stopServerAndWait(w); err != nil {
+					progressDialog.Hide()
+					dialog.ShowError(fmt.Errorf("restore cancelled: %w", err), w)
+					return
+				}
+			}
+			err := restoreBackup(b, version)
+			progressDialog.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("restore failed: %w", err), w)
+			} else {
+				dialog.ShowInformation("Restore Complete", fmt.Sprintf("OWLCMS %s now uses the data saved on %s.", version, b.Time.Format("2006-01-02 15:04:05")), w)
+			}
+			refresh()
+		}()
+	}, w)
+}
+
+// restoreIntoOtherVersion asks for the version that receives a backup, for example to move results to a newer release
+func restoreIntoOtherVersion(b backup, w fyne.Window, refresh func()) {
+	targets := filterVersions(getAllDataKeys(), b.Version)
+	if len(targets) == 0 {
+		dialog.ShowInformation("Restore Into Another Version", "No other version is installed.", w)
+		return
+	}
+	targetSelect := widget.NewSelect(targets, nil)
+	targetSelect.SetSelected(targets[0])
+	dialog.ShowForm("Restore Into Another Version", "Restore", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Version", targetSelect)},
+		func(ok bool) {
+			if ok && targetSelect.Selected != "" {
+				confirmRestore(b, targetSelect.Selected, w, refresh)
+			}
+		}, w)
+}
+
+// exportBackup saves a copy of a backup archive where the user chooses, for example on a USB key
+func exportBackup(b backup, w fyne.Window) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		src, err := os.Open(b.Path)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read backup: %w", err), w)
+			return
+		}
+		defer src.Close()
+		if _, err := io.Copy(writer, src); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to export backup: %w", err), w)
+			return
+		}
+		dialog.ShowInformation("Backup Exported", fmt.Sprintf("Saved %s", writer.URI().Path()), w)
+	}, w)
+	saveDialog.SetFileName(fmt.Sprintf("owlcms_%s_%s", b.Version, filepath.Base(b.Path)))
+	saveDialog.Show()
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// releaseSuffix marks, in the Compare window, the local folder of a version as it was released
+const releaseSuffix = " (release)"
+
+// configTree is the local folder of a version, either as installed or as released
+type configTree struct {
+	files map[string]string // slash-separated path relative to the version directory -> SHA-256
+	read  func(path string) ([]byte, error)
+}
+
+// loadConfigTree reads the local folder of an installed version, or the release copy kept in its
+// pristine archive when release is true
+func loadConfigTree(version string, release bool) (*configTree, error) {
+	versionDir := filepath.Join(owlcmsInstallDir, version)
+	tree := &configTree{files: map[string]string{}}
+	if release {
+		manifest, err := downloadUtils.ReadManifest(filepath.Join(versionDir, downloadUtils.ManifestFileName))
+		if err != nil {
+			return nil, fmt.Errorf("no record of the release files of %s: %w", version, err)
+		}
+		for p, hash := range manifest {
+			if strings.HasPrefix(p, "local/") {
+				tree.files[p] = hash
+			}
+		}
+		archive := filepath.Join(versionDir, downloadUtils.PristineArchiveName)
+		tree.read = func(p string) ([]byte, error) {
+			return downloadUtils.ReadZipEntry(archive, p)
+		}
+		return tree, nil
+	}
+
+	err := filepath.Walk(filepath.Join(versionDir, "local"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(versionDir, path)
+		if err != nil {
+			return err
+		}
+		hash, err := downloadUtils.HashFile(path)
+		if err != nil {
+			return err
+		}
+		tree.files[filepath.ToSlash(relPath)] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tree.read = func(p string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(versionDir, filepath.FromSlash(p)))
+	}
+	return tree, nil
+}
+
+// treeDifference is a file that differs between two local folders
+type treeDifference struct {
+	Path   string
+	Status string // added, removed or changed, going from the left folder to the right one
+}
+
+// compareTrees lists the files added, removed or changed from left to right, sorted by path
+func compareTrees(left, right *configTree) []treeDifference {
+	var differences []treeDifference
+	for p, hash := range left.files {
+		if rightHash, found := right.files[p]; !found {
+			differences = append(differences, treeDifference{Path: p, Status: "removed"})
+		} else if rightHash != hash {
+			differences = append(differences, treeDifference{Path: p, Status: "changed"})
+		}
+	}
+	for p := range right.files {
+		if _, found := left.files[p]; !found {
+			differences = append(differences, treeDifference{Path: p, Status: "added"})
+		}
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+	return differences
+}
+
+// diffRow is a line of a side-by-side comparison. A side is empty where the other has a line of its own.
+type diffRow struct {
+	Left, Right       string
+	HasLeft, HasRight bool
+	Same              bool
+}
+
+// alignLines lines up two texts for a side-by-side view. Lines removed and added at the same place share rows.
+func alignLines(a, b []string) ([]diffRow, bool) {
+	matches, ok := matchLines(a, b)
+	if !ok {
+		return nil, false
+	}
+	var rows []diffRow
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		// the next common line, or the ends of both texts
+		nextA, nextB := len(a), len(b)
+		for k := i; k < len(a); k++ {
+			if matches[k] >= 0 {
+				nextA, nextB = k, matches[k]
+				break
+			}
+		}
+		for i < nextA || j < nextB {
+			row := diffRow{}
+			if i < nextA {
+				row.Left, row.HasLeft = a[i], true
+				i++
+			}
+			if j < nextB {
+				row.Right, row.HasRight = b[j], true
+				j++
+			}
+			rows = append(rows, row)
+		}
+		if i < len(a) {
+			rows = append(rows, diffRow{Left: a[i], Right: b[j], HasLeft: true, HasRight: true, Same: true})
+			i++
+			j++
+		}
+	}
+	return rows, true
+}
+
+// showCompareWindow compares the local folders of two installed versions, or of a version with its release
+func showCompareWindow() {
+	win := fyne.CurrentApp().NewWindow("Compare Configurations")
+	var choices []string
+	for _, version := range getAllInstalledVersions() {
+		choices = append(choices, version)
+		if _, err := os.Stat(filepath.Join(owlcmsInstallDir, version, downloadUtils.PristineArchiveName)); err == nil {
+			choices = append(choices, version+releaseSuffix)
+		}
+	}
+	if len(choices) == 0 {
+		win.SetContent(container.NewPadded(widget.NewLabel("No version is installed.")))
+		win.Resize(fyne.NewSize(400, 150))
+		win.Show()
+		return
+	}
+
+	var left, right *configTree
+	var differences []treeDifference
+	leftGrid := widget.NewTextGrid()
+	rightGrid := widget.NewTextGrid()
+	leftTitle := widget.NewLabel("")
+	rightTitle := widget.NewLabel("")
+	summary := widget.NewLabel("")
+
+	differenceList := widget.NewList(
+		func() int { return len(differences) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fmt.Sprintf("%-8s %s", differences[id].Status, strings.TrimPrefix(differences[id].Path, "local/")))
+		})
+	differenceList.OnSelected = func(id widget.ListItemID) {
+		showFileDifference(left, right, differences[id].Path, leftGrid, rightGrid, win)
+	}
+
+	leftSelect := widget.NewSelect(choices, nil)
+	rightSelect := widget.NewSelect(choices, nil)
+	compare := func() {
+		if leftSelect.Selected == "" || rightSelect.Selected == "" {
+			return
+		}
+		var err error
+		left, err = loadConfigTree(strings.TrimSuffix(leftSelect.Selected, releaseSuffix), strings.HasSuffix(leftSelect.Selected, releaseSuffix))
+		if err == nil {
+			right, err = loadConfigTree(strings.TrimSuffix(rightSelect.Selected, releaseSuffix), strings.HasSuffix(rightSelect.Selected, releaseSuffix))
+		}
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		differences = compareTrees(left, right)
+		summary.SetText(fmt.Sprintf("%d files differ", len(differences)))
+		leftTitle.SetText(leftSelect.Selected)
+		rightTitle.SetText(rightSelect.Selected)
+		leftGrid.SetText("")
+		rightGrid.SetText("")
+		differenceList.UnselectAll()
+		differenceList.Refresh()
+	}
+	leftSelect.OnChanged = func(string) { compare() }
+	rightSelect.OnChanged = func(string) { compare() }
+
+	// by default, show what was changed in the most recent version since it was installed
+	if len(choices) > 1 && strings.HasSuffix(choices[1], releaseSuffix) {
+		leftSelect.SetSelected(choices[1])
+	} else {
+		leftSelect.SetSelected(choices[min(1, len(choices)-1)])
+	}
+	rightSelect.SetSelected(choices[0])
+
+	selects := container.NewGridWithColumns(2,
+		container.NewBorder(nil, nil, widget.NewLabel("From"), nil, leftSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("To"), nil, rightSelect))
+	sides := container.NewGridWithColumns(2,
+		container.NewBorder(leftTitle, nil, nil, nil, leftGrid),
+		container.NewBorder(rightTitle, nil, nil, nil, rightGrid))
+	split := container.NewHSplit(
+		container.NewBorder(summary, nil, nil, nil, differenceList),
+		container.NewScroll(sides))
+	split.Offset = 0.3
+	win.SetContent(container.NewBorder(selects, nil, nil, nil, split))
+	win.Resize(fyne.NewSize(1100, 650))
+	win.Show()
+}
+
+// showFileDifference fills the two grids with the two versions of a file, lined up, with removed lines
+// on the left and added lines on the right highlighted
+func showFileDifference(left, right *configTree, path string, leftGrid, rightGrid *widget.TextGrid, w fyne.Window) {
+	var leftContent, rightContent []byte
+	var err error
+	if _, found := left.files[path]; found {
+		if leftContent, err = left.read(path); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read %s: %w", path, err), w)
+			return
+		}
+	}
+	if _, found := right.files[path]; found {
+		if rightContent, err = right.read(path); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read %s: %w", path, err), w)
+			return
+		}
+	}
+	if !isText(leftContent) || !isText(rightContent) {
+		leftGrid.SetText(fmt.Sprintf("(binary file, %s)", downloadUtils.FormatSize(int64(len(leftContent)))))
+		rightGrid.SetText(fmt.Sprintf("(binary file, %s)", downloadUtils.FormatSize(int64(len(rightContent)))))
+		return
+	}
+	rows, ok := alignLines(splitLines(string(leftContent)), splitLines(string(rightContent)))
+	if !ok {
+		leftGrid.SetText("(file too large to compare)")
+		rightGrid.SetText("(file too large to compare)")
+		return
+	}
+
+	removed := &widget.CustomTextGridStyle{FGColor: theme.Color(theme.ColorNameError)}
+	added := &widget.CustomTextGridStyle{FGColor: theme.Color(theme.ColorNameSuccess)}
+	var leftText, rightText strings.Builder
+	for _, row := range rows {
+		leftText.WriteString(strings.TrimRight(row.Left, "\r\n") + "\n")
+		rightText.WriteString(strings.TrimRight(row.Right, "\r\n") + "\n")
+	}
+	leftGrid.SetText(leftText.String())
+	rightGrid.SetText(rightText.String())
+	for i, row := range rows {
+		if row.Same {
+			continue
+		}
+		if row.HasLeft {
+			leftGrid.SetRowStyle(i, removed)
+		}
+		if row.HasRight {
+			rightGrid.SetRowStyle(i, added)
+		}
+	}
+	leftGrid.Refresh()
+	rightGrid.Refresh()
+}
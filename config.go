@@ -8,6 +8,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	"owlcms-launcher/javacheck"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -41,10 +45,92 @@ func GetPort() string {
 	return port
 }
 
+// GetSetting returns the value of key from env.properties, or def if it is not set
+func GetSetting(key, def string) string {
+	if environment == nil {
+		return def
+	}
+	value, ok := environment.Get(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return def
+	}
+	return strings.TrimSpace(value)
+}
+
+// GetBoolSetting returns true if key is set to true in env.properties
+func GetBoolSetting(key string) bool {
+	value, err := strconv.ParseBool(GetSetting(key, "false"))
+	return err == nil && value
+}
+
+// getEnvFilePath returns the location of the env.properties file shared by all versions
+func getEnvFilePath() string {
+	return filepath.Join(owlcmsInstallDir, "env.properties")
+}
+
+// envLayer is one of the properties files merged to obtain the environment of owlcms
+type envLayer struct {
+	Name string
+	Path string
+}
+
+// getVersionEnvFilePath returns the location of the optional env.properties of an installed version
+func getVersionEnvFilePath(version string) string {
+	return filepath.Join(owlcmsInstallDir, version, "env.properties")
+}
+
+// getEnvLayers lists the properties files used for a version, workspace and profile, lowest precedence first:
+// the shared env.properties, the env.properties of the version directory, that of the workspace, then the profile.
+func getEnvLayers(version, workspace, profile string) []envLayer {
+	layers := []envLayer{{Name: "env.properties", Path: getEnvFilePath()}}
+	if version != "" {
+		if _, err := os.Stat(getVersionEnvFilePath(version)); err == nil {
+			layers = append(layers, envLayer{Name: "version " + version, Path: getVersionEnvFilePath(version)})
+		}
+	}
+	if workspace != "" {
+		workspaceEnv := filepath.Join(getWorkspaceDir(workspace), "env.properties")
+		if _, err := os.Stat(workspaceEnv); err == nil {
+			layers = append(layers, envLayer{Name: "workspace " + workspace, Path: workspaceEnv})
+		}
+	}
+	if profile != "" {
+		layers = append(layers, envLayer{Name: "profile " + profile, Path: getProfileFilePath(profile)})
+	}
+	return layers
+}
+
+// mergeEnvFile layers the variables of a properties file over those already in props
+func mergeEnvFile(props *properties.Properties, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	layer := properties.NewProperties()
+	if err := layer.Load(content, properties.UTF8); err != nil {
+		return err
+	}
+	props.Merge(layer)
+	return nil
+}
+
+// InitEnv loads the environment, including the overrides of the running version if there is one
 func InitEnv() {
+	version := ""
+	if currentProcess != nil {
+		version = currentVersion
+	}
+	InitVersionEnv(version)
+}
+
+// InitVersionEnv loads the environment used to launch a version. env.properties is created if missing.
+func InitVersionEnv(version string) {
 	// Check for the presence of env.properties file in the owlcmsInstallDir
 	props := properties.NewProperties()
-	envFilePath := filepath.Join(owlcmsInstallDir, "env.properties")
+	envFilePath := getEnvFilePath()
+	if err := os.MkdirAll(owlcmsInstallDir, 0755); err != nil {
+		log.Fatalf("Failed to create owlcms directory: %v", err)
+	}
 	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
 		// Create env.properties file with entry "OWLCMS_PORT=8080"
 		props.Set("OWLCMS_PORT", "8080")
@@ -66,7 +152,41 @@ func InitEnv() {
 #OWLCMS_FEATURESWITCHES=interimScores
 
 # java options can be set with this variable (remove the leading # to uncomment)
-#JAVA_OPTIONS=-Xmx512m -Xmx512m`
+# in this file, write Windows paths with forward slashes or doubled backslashes: -Djava.io.tmpdir=C:/temp
+#JAVA_OPTIONS=-Xmx512m -Xmx512m
+
+# arguments given to owlcms after the jar name; quote arguments containing spaces (remove the leading # to uncomment)
+#PROGRAM_ARGUMENTS=
+
+# advertise the running server on the local network as http://owlcms.local (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_MDNS=true
+#OWLCMS_LAUNCHER_MDNSNAME=owlcms
+
+# HTTPS port with a locally generated certificate, needed by tablets for camera and microphone (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_HTTPSPORT=8443
+
+# use the Java from JAVA_HOME or the PATH when it is recent enough, instead of downloading one (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_SYSTEMJAVA=true
+
+# vendor of the downloaded Java runtimes: Temurin, Zulu or Liberica (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_JAVAVENDOR=Temurin
+
+# backups of the database and local folders, made when the server stops and before an update or import
+# (remove the leading # to uncomment; 0 keeps all backups)
+#OWLCMS_LAUNCHER_BACKUPDIR=
+#OWLCMS_LAUNCHER_BACKUPKEEP=20
+
+# back up the database of the running server every so many minutes, optionally copied to a second folder such as a USB drive
+# (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_BACKUPINTERVAL=15
+#OWLCMS_LAUNCHER_BACKUPMIRROR=
+
+# retention policy for old versions, applied from File > Storage or after installations and updates;
+# their data is backed up before they are removed (remove the leading # to uncomment)
+#OWLCMS_LAUNCHER_KEEPSTABLE=3
+#OWLCMS_LAUNCHER_KEEPPRERELEASES=2
+#OWLCMS_LAUNCHER_KEEPRECENTDAYS=30
+#OWLCMS_LAUNCHER_CLEANUPAFTERUPDATE=true`
 
 		if _, err := file.WriteString(rawString); err != nil {
 			log.Fatalf("Failed to write comment to env.properties file: %v", err)
@@ -75,6 +195,19 @@ func InitEnv() {
 
 	// Load the properties into the global variable environment
 	loadProperties(envFilePath)
+
+	// Layer the version and workspace overrides and the selected profile over the shared variables
+	for _, layer := range getEnvLayers(version, selectedWorkspace, selectedProfile)[1:] {
+		if err := mergeEnvFile(environment, layer.Path); err != nil {
+			log.Printf("Failed to load %s: %v", layer.Name, err)
+			continue
+		}
+		log.Printf("Using %s from %s", layer.Name, layer.Path)
+	}
+	javacheck.SetPreferSystemJava(GetBoolSetting("OWLCMS_LAUNCHER_SYSTEMJAVA"))
+	if err := javacheck.SetJavaVendor(GetSetting("OWLCMS_LAUNCHER_JAVAVENDOR", "")); err != nil {
+		log.Printf("%v, using %s", err, javacheck.JavaVendor())
+	}
 }
 
 func loadProperties(envFilePath string) {
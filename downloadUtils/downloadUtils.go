@@ -4,11 +4,16 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -48,6 +53,64 @@ func DownloadArchive(url, destPath string) error {
 	return nil
 }
 
+// VerifyArchive checks the size and checksum of a downloaded file. The checksum is a hexadecimal
+// SHA-256, or a SHA-1 for the vendors that only publish that. A zero size or an empty checksum is not checked.
+func VerifyArchive(path string, expectedSize int64, expectedChecksum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var hasher hash.Hash
+	if len(expectedChecksum) == sha1.Size*2 {
+		hasher = sha1.New()
+	} else {
+		hasher = sha256.New()
+	}
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if expectedSize > 0 && size != expectedSize {
+		return fmt.Errorf("size of %s is %d bytes, expected %d", filepath.Base(path), size, expectedSize)
+	}
+	if expectedChecksum != "" {
+		actual := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(actual, expectedChecksum) {
+			return fmt.Errorf("checksum of %s is %s, expected %s", filepath.Base(path), actual, expectedChecksum)
+		}
+		log.Printf("Verified checksum of %s\n", path)
+	}
+	return nil
+}
+
+// DirSize returns the total size in bytes of the files under a directory
+func DirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// FormatSize returns a size in bytes as a short human-readable string
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 // IsWSL checks if the program is running under Windows Subsystem for Linux.
 func IsWSL() bool {
 	_, err := os.Stat("/proc/version")
@@ -84,12 +147,32 @@ func GetGoos() string {
 	return runtime.GOOS
 }
 
-// ExtractZip extracts a zip archive to the specified destination directory.
+// ExtractZip extracts a zip archive to the specified destination directory, then removes the archive.
 func ExtractZip(src, dest string) error {
+	if err := ExtractZipFile(src, dest); err != nil {
+		return err
+	}
+
+	// Remove the downloaded ZIP file
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove downloaded file %s: %w", src, err)
+	}
+
+	return nil
+}
+
+// ExtractZipFile extracts a zip archive to the specified destination directory and leaves the archive in place.
+func ExtractZipFile(src, dest string) error {
+	return extractZip(src, dest, nil)
+}
+
+// extractZip extracts a zip archive and, if manifest is not nil, records the hash of each extracted file in it
+func extractZip(src, dest string, manifest Manifest) error {
 	r, err := zip.OpenReader(src)
 	if err != nil {
 		return fmt.Errorf("failed to open zip file %s: %w", src, err)
 	}
+	defer r.Close()
 
 	for _, f := range r.File {
 		if f.Name == "Procfile" || f.Name == "system.properties" {
@@ -117,7 +200,13 @@ func ExtractZip(src, dest string) error {
 			return fmt.Errorf("failed to open file inside zip: %w", err)
 		}
 
-		_, err = io.Copy(outFile, rc)
+		if manifest != nil {
+			hasher := sha256.New()
+			_, err = io.Copy(io.MultiWriter(outFile, hasher), rc)
+			manifest[path.Clean(f.Name)] = hex.EncodeToString(hasher.Sum(nil))
+		} else {
+			_, err = io.Copy(outFile, rc)
+		}
 
 		outFile.Close()
 		rc.Close()
@@ -132,14 +221,74 @@ func ExtractZip(src, dest string) error {
 		}
 	}
 
-	r.Close() // Close the zip file after extracting
+	return nil
+}
 
-	// Remove the downloaded ZIP file
-	if err := os.Remove(src); err != nil {
-		return fmt.Errorf("failed to remove downloaded file %s: %w", src, err)
+// CreateZip archives the given subdirectories of baseDir into zipPath, keeping their paths relative to baseDir.
+// Missing subdirectories are skipped. The archive is written under a temporary name and renamed when complete,
+// so an interrupted run never leaves a truncated archive behind.
+func CreateZip(zipPath, baseDir string, subdirs ...string) error {
+	tempPath := zipPath + ".part"
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tempPath, err)
 	}
+	zw := zip.NewWriter(out)
 
-	return nil
+	for _, subdir := range subdirs {
+		root := filepath.Join(baseDir, subdir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(baseDir, path)
+			if err != nil {
+				return err
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(relPath)
+			if info.IsDir() {
+				header.Name += "/"
+				_, err = zw.CreateHeader(header)
+				return err
+			}
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(w, f)
+			return err
+		})
+		if err != nil {
+			zw.Close()
+			out.Close()
+			os.Remove(tempPath)
+			return fmt.Errorf("failed to add %s to %s: %w", subdir, filepath.Base(zipPath), err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", zipPath, err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", zipPath, err)
+	}
+	return os.Rename(tempPath, zipPath)
 }
 
 // ExtractTarGz extracts a tar.gz archive to the specified destination directory.
@@ -0,0 +1,145 @@
+package downloadUtils
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFileName is the name of the manifest written at the top of an installed version
+const ManifestFileName = "pristine.sha256"
+
+// PristineArchiveName is the zip, kept at the top of an installed version, holding the files of the release
+// that users customize, as they were released. It is the common ancestor when merging customized files.
+const PristineArchiveName = "pristine-local.zip"
+
+// Manifest maps the slash-separated path of each file of a release, relative to the installation
+// directory, to its hexadecimal SHA-256. It tells which files were modified or added after installation.
+type Manifest map[string]string
+
+// ExtractZipWithManifest extracts a release zip like ExtractZip, writes the manifest of the extracted
+// files in dest, in the format of sha256sum, and keeps a copy of the release's local folder
+func ExtractZipWithManifest(src, dest string) error {
+	manifest := Manifest{}
+	if err := extractZip(src, dest, manifest); err != nil {
+		return err
+	}
+	if err := WriteManifest(filepath.Join(dest, ManifestFileName), manifest); err != nil {
+		return err
+	}
+	if err := copyZipEntries(src, filepath.Join(dest, PristineArchiveName), "local/"); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove downloaded file %s: %w", src, err)
+	}
+	return nil
+}
+
+// WriteManifest saves a manifest as "<sha256>  <path>" lines, sorted by path
+func WriteManifest(manifestPath string, manifest Manifest) error {
+	paths := make([]string, 0, len(manifest))
+	for p := range manifest {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%s  %s\n", manifest[p], p)
+	}
+	if err := os.WriteFile(manifestPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", manifestPath, err)
+	}
+	return nil
+}
+
+// ReadManifest loads the manifest of an installed version
+func ReadManifest(manifestPath string) (Manifest, error) {
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	manifest := Manifest{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, p, ok := strings.Cut(scanner.Text(), "  ")
+		if ok {
+			manifest[p] = hash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// HashFile returns the hexadecimal SHA-256 of a file
+func HashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// copyZipEntries writes the entries of a zip whose names start with prefix to a new zip, without recompressing them
+func copyZipEntries(src, dest, prefix string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to open zip file %s: %w", src, err)
+	}
+	defer r.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	w := zip.NewWriter(out)
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, prefix) || f.FileInfo().IsDir() {
+			continue
+		}
+		if err = w.Copy(f); err != nil {
+			break
+		}
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+// ReadZipEntry returns the content of the file named name in a zip archive
+func ReadZipEntry(zipPath, name string) ([]byte, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"owlcms-launcher/javacheck"
+)
+
+type envSettingKind int
+
+const (
+	textSetting envSettingKind = iota
+	boolSetting
+	intSetting
+	choiceSetting
+	argumentsSetting // command-line arguments, split with splitCommandLine
+)
+
+// envSetting describes a variable that can be set in env.properties
+type envSetting struct {
+	Key         string
+	Kind        envSettingKind
+	Choices     []string // allowed values for choiceSetting
+	Min, Max    int      // range for intSetting
+	Description string
+	Launcher    bool // true if the variable is used by the launcher rather than by owlcms
+}
+
+// envSchema lists the known owlcms and launcher variables, in the order shown in the Settings window
+var envSchema = []envSetting{
+	{Key: "OWLCMS_PORT", Kind: intSetting, Min: 1, Max: 65535,
+		Description: "Port on which owlcms listens (default 8080)"},
+	{Key: "OWLCMS_INITIALDATA", Kind: choiceSetting, Choices: []string{"EMPTY_COMPETITION", "LARGEGROUP_DEMO", "BENCHMARK"},
+		Description: "Data loaded when the database is created or reset"},
+	{Key: "OWLCMS_RESETMODE", Kind: boolSetting,
+		Description: "Recreate the database at every start"},
+	{Key: "OWLCMS_MEMORYMODE", Kind: boolSetting,
+		Description: "Keep the database in memory only; nothing is saved"},
+	{Key: "OWLCMS_FEATURESWITCHES", Kind: textSetting,
+		Description: "Comma-separated feature toggles, overriding those in the database"},
+	{Key: "OWLCMS_LOCALE", Kind: textSetting,
+		Description: "Language used by default, for example fr or es_ES"},
+	{Key: "JAVA_OPTIONS", Kind: argumentsSetting,
+		Description: "Options for the Java virtual machine, placed before -jar, for example -Xmx512m"},
+	{Key: "PROGRAM_ARGUMENTS", Kind: argumentsSetting,
+		Description: "Arguments given to owlcms, placed after the jar name; quote arguments containing spaces"},
+	{Key: "OWLCMS_LAUNCHER_MDNS", Kind: boolSetting, Launcher: true,
+		Description: "Advertise the running server on the local network"},
+	{Key: "OWLCMS_LAUNCHER_MDNSNAME", Kind: textSetting, Launcher: true,
+		Description: "Name advertised on the local network (default owlcms, for http://owlcms.local)"},
+	{Key: "OWLCMS_LAUNCHER_HTTPSPORT", Kind: intSetting, Min: 1, Max: 65535, Launcher: true,
+		Description: "Port of the HTTPS front-end with a locally generated certificate; empty to disable"},
+	{Key: "OWLCMS_LAUNCHER_SYSTEMJAVA", Kind: boolSetting, Launcher: true,
+		Description: "Use the Java from JAVA_HOME or the PATH when recent enough, instead of downloading one"},
+	{Key: "OWLCMS_LAUNCHER_JAVAVENDOR", Kind: choiceSetting, Choices: javacheck.JavaVendors, Launcher: true,
+		Description: "Vendor of the downloaded Java runtimes (default Temurin); existing runtimes are replaced from Java Runtimes"},
+	{Key: "OWLCMS_LAUNCHER_BACKUPDIR", Kind: textSetting, Launcher: true,
+		Description: "Folder where the database and local files are backed up (default: backups in the installation directory)"},
+	{Key: "OWLCMS_LAUNCHER_BACKUPKEEP", Kind: intSetting, Min: 0, Max: 10000, Launcher: true,
+		Description: "Number of backups of each kind kept for each version (default 20, 0 keeps them all)"},
+	{Key: "OWLCMS_LAUNCHER_BACKUPINTERVAL", Kind: intSetting, Min: 0, Max: 1440, Launcher: true,
+		Description: "Minutes between backups of the running server's database; empty or 0 to disable"},
+	{Key: "OWLCMS_LAUNCHER_BACKUPMIRROR", Kind: textSetting, Launcher: true,
+		Description: "Second folder receiving a copy of the scheduled backups, for example on a USB drive"},
+	{Key: "OWLCMS_LAUNCHER_KEEPSTABLE", Kind: intSetting, Min: 1, Max: 100, Launcher: true,
+		Description: "Number of stable versions kept when old versions are removed from Storage; empty keeps them all"},
+	{Key: "OWLCMS_LAUNCHER_KEEPPRERELEASES", Kind: intSetting, Min: 1, Max: 100, Launcher: true,
+		Description: "Number of prereleases kept when old versions are removed from Storage; empty keeps them all"},
+	{Key: "OWLCMS_LAUNCHER_KEEPRECENTDAYS", Kind: intSetting, Min: 0, Max: 3650, Launcher: true,
+		Description: "Versions whose database changed within this many days are never removed by the retention policy"},
+	{Key: "OWLCMS_LAUNCHER_CLEANUPAFTERUPDATE", Kind: boolSetting, Launcher: true,
+		Description: "Offer to remove the versions beyond those kept after each installation or update"},
+}
+
+// findEnvSetting returns the schema entry for key, or nil if the key is not known
+func findEnvSetting(key string) *envSetting {
+	for i := range envSchema {
+		if envSchema[i].Key == key {
+			return &envSchema[i]
+		}
+	}
+	return nil
+}
+
+// validate checks a value against the schema entry. Empty values are always accepted and mean "not set".
+func (s *envSetting) validate(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	switch s.Kind {
+	case boolSetting:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false", s.Key)
+		}
+	case intSetting:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number", s.Key)
+		}
+		if n < s.Min || n > s.Max {
+			return fmt.Errorf("%s must be between %d and %d", s.Key, s.Min, s.Max)
+		}
+	case choiceSetting:
+		for _, choice := range s.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", s.Key, strings.Join(s.Choices, ", "))
+	case argumentsSetting:
+		if _, err := splitCommandLine(value); err != nil {
+			return fmt.Errorf("%s: %w", s.Key, err)
+		}
+	}
+	return nil
+}
+
+// suggestEnvKey returns the known key closest to an unknown one, to catch typos such as OWLCMS_INTIALDATA
+func suggestEnvKey(key string) string {
+	best := ""
+	bestDistance := 3 // only suggest keys that are at most 2 edits away
+	for _, setting := range envSchema {
+		if d := editDistance(strings.ToUpper(key), setting.Key); d < bestDistance {
+			best = setting.Key
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -5,6 +5,7 @@ go 1.23.4
 require (
 	fyne.io/fyne/v2 v2.5.3
 	github.com/gofrs/flock v0.12.1
+	github.com/hashicorp/mdns v1.0.4
 	github.com/magiconair/properties v1.8.9
 	github.com/shirou/gopsutil v3.21.11+incompatible
 )
@@ -13,6 +14,7 @@ require (
 	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/jeandeaual/go-locale v0.0.0-20241217141322-fcc2cadd6f08 // indirect
+	github.com/miekg/dns v1.1.41 // indirect
 	github.com/nicksnyder/go-i18n/v2 v2.4.1 // indirect
 	github.com/rogpeppe/go-internal v1.13.1 // indirect
 	github.com/rymdport/portal v0.3.0 // indirect
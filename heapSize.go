@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/mem"
+)
+
+const (
+	mebibyte    = 1024 * 1024
+	minimumHeap = 256 * mebibyte  // below this owlcms does not start reliably
+	maximumHeap = 4096 * mebibyte // more than this is not needed, even for large competitions
+	// a 32-bit JVM cannot reserve much more than 2GB of contiguous address space, less on ARM
+	maximumHeap32 = 1536 * mebibyte
+	heapRounding  = 64 * mebibyte
+)
+
+// parseMemorySize parses a JVM memory size such as 512m, 2g, 1048576k or 536870912
+func parseMemorySize(s string) (uint64, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	multiplier := uint64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k':
+			multiplier = 1024
+		case 'm':
+			multiplier = mebibyte
+		case 'g':
+			multiplier = 1024 * mebibyte
+		case 't':
+			multiplier = 1024 * 1024 * mebibyte
+		}
+		if multiplier != 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// formatMemorySize returns a size in the unit used by -Xmx
+func formatMemorySize(bytes uint64) string {
+	if bytes%(1024*mebibyte) == 0 {
+		return fmt.Sprintf("%dg", bytes/(1024*mebibyte))
+	}
+	return fmt.Sprintf("%dm", bytes/mebibyte)
+}
+
+// findMaxHeap returns the heap size requested by -Xmx or -XX:MaxHeapSize in the JVM options.
+// As with java, the last occurrence wins.
+func findMaxHeap(jvmOptions []string) (uint64, bool) {
+	var heap uint64
+	found := false
+	for _, option := range jvmOptions {
+		var size string
+		switch {
+		case strings.HasPrefix(option, "-Xmx"):
+			size = strings.TrimPrefix(option, "-Xmx")
+		case strings.HasPrefix(option, "-XX:MaxHeapSize="):
+			size = strings.TrimPrefix(option, "-XX:MaxHeapSize=")
+		default:
+			continue
+		}
+		if n, err := parseMemorySize(size); err == nil {
+			heap = n
+			found = true
+		}
+	}
+	return heap, found
+}
+
+// is32Bit tells if the architecture of the launcher is a 32-bit one. runtime.GOARCH describes the launcher
+// binary, not the JVM: the runtime downloaded for it matches, but a system Java may not.
+func is32Bit() bool {
+	return runtime.GOARCH == "arm" || runtime.GOARCH == "386"
+}
+
+// recommendedHeap computes a heap size from the machine memory: a third of the total, capped by
+// what is currently available so the system does not start swapping, and rounded down to 64MB.
+// The heap of a 32-bit runtime is kept within what it can address.
+func recommendedHeap(total, available uint64) uint64 {
+	heap := total / 3
+	if available > 0 && heap > available*3/4 {
+		heap = available * 3 / 4
+	}
+	ceiling := uint64(maximumHeap)
+	if is32Bit() {
+		ceiling = maximumHeap32
+	}
+	heap = min(max(heap, minimumHeap), ceiling)
+	return heap / heapRounding * heapRounding
+}
+
+// adjustHeap adds a -Xmx option computed from the machine memory when none is configured, or checks
+// the configured one against the memory of the machine. It returns the options and a message for the status.
+func adjustHeap(jvmOptions []string) ([]string, string) {
+	memory, err := mem.VirtualMemory()
+	if err != nil {
+		log.Printf("Failed to get memory information, heap size left to Java: %v\n", err)
+		return jvmOptions, ""
+	}
+	log.Printf("Machine memory: total %s, available %s\n", formatMemorySize(memory.Total), formatMemorySize(memory.Available))
+
+	for _, option := range jvmOptions {
+		if strings.HasPrefix(option, "-XX:MaxRAMPercentage=") || strings.HasPrefix(option, "-XX:MaxRAM=") {
+			return jvmOptions, fmt.Sprintf("Java heap: sized by Java from %s", option)
+		}
+	}
+
+	configured, found := findMaxHeap(jvmOptions)
+	if !found {
+		heap := recommendedHeap(memory.Total, memory.Available)
+		log.Printf("No heap size configured, using -Xmx%s\n", formatMemorySize(heap))
+		return append(jvmOptions, "-Xmx"+formatMemorySize(heap)),
+			fmt.Sprintf("Java heap: %s (automatic, %s of memory)", formatMemorySize(heap), formatMemorySize(memory.Total))
+	}
+
+	message := fmt.Sprintf("Java heap: %s (from JAVA_OPTIONS)", formatMemorySize(configured))
+	if is32Bit() && configured > maximumHeap32 {
+		log.Printf("Configured heap %s exceeds the %s a 32-bit Java can reserve\n", formatMemorySize(configured), formatMemorySize(maximumHeap32))
+		message += fmt.Sprintf("\nWarning: a 32-bit Java cannot reserve more than about %s; Java may fail to start", formatMemorySize(maximumHeap32))
+	} else if configured > memory.Total/4*3 {
+		log.Printf("Configured heap %s exceeds 3/4 of the machine memory %s\n", formatMemorySize(configured), formatMemorySize(memory.Total))
+		message += fmt.Sprintf("\nWarning: this is more than 3/4 of the %s of memory; the system may become unresponsive", formatMemorySize(memory.Total))
+	} else if configured > memory.Available {
+		log.Printf("Configured heap %s exceeds available memory %s\n", formatMemorySize(configured), formatMemorySize(memory.Available))
+		message += fmt.Sprintf("\nWarning: only %s of memory is available; close other programs to avoid swapping", formatMemorySize(memory.Available))
+	}
+	return jvmOptions, message
+}
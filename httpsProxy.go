@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+const caCertificatePath = "/owlcms-ca.crt" // URL path where the proxy offers the CA certificate
+
+var (
+	certsDir    = filepath.Join(owlcmsInstallDir, "certs")
+	caCertFile  = filepath.Join(certsDir, "owlcms-ca.crt")
+	caKeyFile   = filepath.Join(certsDir, "owlcms-ca.key")
+	httpsServer *http.Server // non-nil while the HTTPS front-end is running
+)
+
+// getHTTPSPort returns the port of the HTTPS front-end, or "" if OWLCMS_LAUNCHER_HTTPSPORT is not set
+func getHTTPSPort() string {
+	return GetSetting("OWLCMS_LAUNCHER_HTTPSPORT", "")
+}
+
+// startHTTPSProxy terminates TLS on the HTTPS port and forwards everything, including
+// the WebSockets used by Vaadin push, to owlcms on localhost.
+// Browsers only allow camera and microphone access on secure origins.
+func startHTTPSProxy() (string, error) {
+	httpsPort := getHTTPSPort()
+	if httpsPort == "" {
+		return "", nil
+	}
+	stopHTTPSProxy()
+
+	caCert, caKey, err := loadOrCreateCA()
+	if err != nil {
+		return "", err
+	}
+	serverCert, err := createServerCertificate(caCert, caKey)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(fmt.Sprintf("http://localhost:%s", GetPort()))
+	if err != nil {
+		return "", fmt.Errorf("parsing owlcms URL: %w", err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.FlushInterval = -1 // do not buffer server push responses
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Header.Set("X-Forwarded-Proto", "https")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(caCertificatePath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+		w.Header().Set("Content-Disposition", "attachment; filename=owlcms-ca.crt")
+		http.ServeFile(w, r, caCertFile)
+	})
+	mux.Handle("/", proxy)
+
+	listener, err := net.Listen("tcp", ":"+httpsPort)
+	if err != nil {
+		return "", fmt.Errorf("listening on HTTPS port %s: %w", httpsPort, err)
+	}
+	server := &http.Server{
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	httpsServer = server
+	go func() {
+		if err := server.ServeTLS(listener, "", ""); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTPS front-end stopped: %v\n", err)
+		}
+	}()
+
+	secureURL := fmt.Sprintf("https://localhost:%s", httpsPort)
+	if ips := getLocalIPs(); len(ips) > 0 {
+		secureURL = fmt.Sprintf("https://%s:%s", ips[0], httpsPort)
+	}
+	log.Printf("HTTPS front-end listening on port %s, forwarding to %s\n", httpsPort, target)
+	return secureURL, nil
+}
+
+// stopHTTPSProxy closes the HTTPS front-end and all the connections going through it
+func stopHTTPSProxy() {
+	if httpsServer == nil {
+		return
+	}
+	if err := httpsServer.Close(); err != nil {
+		log.Printf("Failed to stop HTTPS front-end: %v\n", err)
+	}
+	httpsServer = nil
+	log.Println("Stopped HTTPS front-end")
+}
+
+// loadOrCreateCA returns the local certificate authority, creating it on first use.
+// The CA is kept so that devices only need to trust it once.
+func loadOrCreateCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, certErr := os.ReadFile(caCertFile)
+	keyPEM, keyErr := os.ReadFile(caKeyFile)
+	if certErr == nil && keyErr == nil {
+		certBlock, _ := pem.Decode(certPEM)
+		keyBlock, _ := pem.Decode(keyPEM)
+		if certBlock != nil && keyBlock != nil {
+			cert, err := x509.ParseCertificate(certBlock.Bytes)
+			if err == nil && time.Now().Before(cert.NotAfter) {
+				key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+				if err == nil {
+					return cert, key, nil
+				}
+			}
+		}
+		log.Printf("Local certificate authority in %s is not usable, creating a new one\n", certsDir)
+	}
+
+	if err := os.MkdirAll(certsDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("creating certificate directory: %w", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating CA key: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	template := &x509.Certificate{
+		SerialNumber:          randomSerialNumber(),
+		Subject:               pkix.Name{Organization: []string{"owlcms"}, CommonName: "owlcms local CA " + hostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding CA key: %w", err)
+	}
+	if err := os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, nil, fmt.Errorf("writing CA certificate: %w", err)
+	}
+	if err := os.WriteFile(caKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, nil, fmt.Errorf("writing CA key: %w", err)
+	}
+	log.Printf("Created local certificate authority %s\n", caCertFile)
+	return cert, key, nil
+}
+
+// createServerCertificate issues a certificate for the current LAN addresses, signed by the local CA.
+// It is recreated at every start because the addresses change from one venue to the next.
+func createServerCertificate(caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("generating server key: %w", err)
+	}
+
+	dnsNames := []string{"localhost", GetSetting("OWLCMS_LAUNCHER_MDNSNAME", "owlcms") + ".local"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		dnsNames = append(dnsNames, hostname)
+	}
+	ips := append([]net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, getLocalIPs()...)
+
+	template := &x509.Certificate{
+		SerialNumber: randomSerialNumber(),
+		Subject:      pkix.Name{Organization: []string{"owlcms"}, CommonName: dnsNames[len(dnsNames)-1]},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("creating server certificate: %w", err)
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der, caCert.Raw},
+		PrivateKey:  key,
+	}, nil
+}
+
+func randomSerialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
+
+// exportCACertificate lets the user save the CA certificate, to be installed as trusted on tablets and laptops
+func exportCACertificate(w fyne.Window) {
+	if _, _, err := loadOrCreateCA(); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to create certificate authority: %w", err), w)
+		return
+	}
+	data, err := os.ReadFile(caCertFile)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to read CA certificate: %w", err), w)
+		return
+	}
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if _, err := writer.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save CA certificate: %w", err), w)
+			return
+		}
+		dialog.ShowInformation("Certificate Saved",
+			"Install this certificate as a trusted authority on each device that connects with HTTPS.\n"+
+				"It can also be downloaded from the server at "+caCertificatePath, w)
+	}, w)
+	saveDialog.SetFileName("owlcms-ca.crt")
+	saveDialog.Show()
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+type importStatus int
+
+const (
+	importDatabase         importStatus = iota
+	importNew                           // not present in the destination
+	importModifiedInSource              // the destination has the release file, or an older copy
+	importConflict                      // the destination file was also modified
+)
+
+func (s importStatus) String() string {
+	switch s {
+	case importDatabase:
+		return "database"
+	case importNew:
+		return "new"
+	case importModifiedInSource:
+		return "modified in source"
+	default:
+		return "modified in both"
+	}
+}
+
+// What to do with a file modified in both versions
+const (
+	keepDestination = "Keep"
+	overwriteFile   = "Overwrite"
+	keepBoth        = "Keep Both"
+)
+
+// importItem is a file that can be copied from the source version
+type importItem struct {
+	Path       string // slash-separated, relative to the version directory
+	Status     importStatus
+	Selected   bool
+	Resolution string // for conflicts
+}
+
+// planImport lists the database files and the local files added or modified in the source version,
+// with their status in the destination. Files identical in both versions are left out.
+func planImport(srcDir, destDir string) ([]*importItem, error) {
+	var items []*importItem
+	databaseDir := filepath.Join(srcDir, "database")
+	err := filepath.Walk(databaseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			relPath, _ := filepath.Rel(srcDir, path)
+			items = append(items, &importItem{Path: filepath.ToSlash(relPath), Status: importDatabase, Selected: true})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := changedLocalFiles(srcDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	destManifest, _ := downloadUtils.ReadManifest(filepath.Join(destDir, downloadUtils.ManifestFileName))
+	for _, change := range changes {
+		srcFile := filepath.Join(srcDir, filepath.FromSlash(change.Path))
+		destFile := filepath.Join(destDir, filepath.FromSlash(change.Path))
+		item := &importItem{Path: change.Path, Selected: true}
+		destHash, err := downloadUtils.HashFile(destFile)
+		if err != nil {
+			item.Status = importNew
+			items = append(items, item)
+			continue
+		}
+		srcHash, err := downloadUtils.HashFile(srcFile)
+		if err != nil {
+			return nil, err
+		}
+		if srcHash == destHash {
+			continue
+		}
+		// without a manifest, any difference in the destination counts as a modification
+		if pristine, known := destManifest[change.Path]; known && pristine == destHash {
+			item.Status = importModifiedInSource
+		} else {
+			item.Status = importConflict
+			item.Resolution = keepBoth
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// keepBothPath returns the name under which a conflicting file is imported next to the destination one,
+// e.g. local/templates/cards.xlsx -> local/templates/cards (55.0.0).xlsx
+func keepBothPath(path, sourceVersion string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s (%s)%s", strings.TrimSuffix(path, ext), sourceVersion, ext)
+}
+
+// applyImport copies the selected files. The destination has been backed up before. Files modified in the
+// source only are merged with the changes of the destination release; the merges with conflicts are returned.
+func applyImport(items []*importItem, srcDir, destDir, sourceVersion string) (int, []*fileMerge, error) {
+	copied := 0
+	var conflicts []*fileMerge
+	for _, item := range items {
+		if !item.Selected || (item.Status == importConflict && item.Resolution == keepDestination) {
+			continue
+		}
+		if item.Status == importModifiedInSource {
+			conflict, err := carryLocalFile(srcDir, destDir, item.Path)
+			if err != nil {
+				return copied, conflicts, fmt.Errorf("importing %s: %w", item.Path, err)
+			}
+			if conflict != nil {
+				conflicts = append(conflicts, conflict)
+			}
+			copied++
+			continue
+		}
+		destPath := item.Path
+		if item.Status == importConflict && item.Resolution == keepBoth {
+			destPath = keepBothPath(item.Path, sourceVersion)
+		}
+		src := filepath.Join(srcDir, filepath.FromSlash(item.Path))
+		dest := filepath.Join(destDir, filepath.FromSlash(destPath))
+		log.Printf("Importing %s to %s\n", src, dest)
+		if err := copyFile(src, dest); err != nil {
+			return copied, conflicts, fmt.Errorf("importing %s: %w", item.Path, err)
+		}
+		copied++
+	}
+	return copied, conflicts, nil
+}
+
+// showImportPreview lists what would be imported from another version and lets the user choose
+func showImportPreview(sourceVersion, version string, w fyne.Window) {
+	srcDir := filepath.Join(owlcmsInstallDir, sourceVersion)
+	destDir := filepath.Join(owlcmsInstallDir, version)
+	items, err := planImport(srcDir, destDir)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to compare %s and %s: %w", sourceVersion, version, err), w)
+		return
+	}
+	if len(items) == 0 {
+		dialog.ShowInformation("Import Data and Config", fmt.Sprintf("Version %s has no data or modified files to import.", sourceVersion), w)
+		return
+	}
+
+	rows := container.NewVBox()
+	for _, item := range items {
+		item := item
+		check := widget.NewCheck(item.Path, func(checked bool) {
+			item.Selected = checked
+		})
+		check.SetChecked(item.Selected)
+		status := widget.NewLabel(item.Status.String())
+		if item.Status == importConflict {
+			resolution := widget.NewSelect([]string{keepDestination, overwriteFile, keepBoth}, func(selected string) {
+				item.Resolution = selected
+			})
+			resolution.SetSelected(item.Resolution)
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(status, resolution), check))
+		} else {
+			rows.Add(container.NewBorder(nil, nil, nil, status, check))
+		}
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(700, 350))
+	header := widget.NewLabel(fmt.Sprintf("Files to import from %s into %s. The data of %s is backed up first.\n"+
+		"For files modified in both versions, Keep Both imports the file of %s under a name ending with (%s).",
+		sourceVersion, version, version, sourceVersion, sourceVersion))
+
+	dialog.ShowCustomConfirm("Import Data and Config", "Import", "Cancel", container.NewBorder(header, nil, nil, nil, scroll), func(ok bool) {
+		if !ok {
+			return
+		}
+		if currentProcess != nil && currentVersion == version {
+			dialog.ShowError(fmt.Errorf("stop OWLCMS %s before importing into it", version), w)
+			return
+		}
+		// Keep the data about to be overwritten
+		if _, err := backupVersion(version, "import"); err != nil {
+			dialog.ShowError(fmt.Errorf("import cancelled, the current data could not be backed up: %w", err), w)
+			return
+		}
+		copied, conflicts, err := applyImport(items, srcDir, destDir, sourceVersion)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("import stopped after %d files, the previous data is in the Backups window: %w", copied, err), w)
+			return
+		}
+		dialog.ShowInformation("Import Complete", fmt.Sprintf("Imported %d files from version %s to version %s", copied, sourceVersion, version), w)
+		if len(conflicts) > 0 {
+			showMergeConflicts(version, conflicts)
+		}
+	}, w)
+}
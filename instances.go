@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Masterminds/semver/v3"
+	"github.com/magiconair/properties"
+)
+
+// instanceFileName is written in the directory of an installation whose name is not its version,
+// such as a second copy of a version used by another platform
+const instanceFileName = "install.properties"
+
+var (
+	releaseNamePattern  = regexp.MustCompile(`^\d+\.\d+\.\d+(?:-(?:rc|alpha|beta)(?:\d+)?)?$`)
+	instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 ._-]*$`)
+	// folders of the installation directory that cannot be used as instance names
+	reservedNamePattern = regexp.MustCompile(`(?i)^(java\d+|backups|previous|workspaces|archives)$`)
+)
+
+// instanceInfo is what install.properties records about an installation: where it came from, and how
+// it was used since. Times are in RFC 3339 format.
+type instanceInfo struct {
+	Version      string // the owlcms release
+	Label        string // free text shown in the version list
+	Source       string // address of the downloaded zip
+	Checksum     string // SHA-256 of the downloaded zip
+	Installed    string // when the release was installed or updated to
+	UpdatedFrom  string // release of the installation whose data was carried over by an update
+	Channel      string // stable or prerelease
+	Size         int64  // bytes of the installation when installed
+	Java         string // runtime used by the last launch
+	LastLaunched string
+	Notes        string
+}
+
+// instanceKeys are the text entries of install.properties besides version, in the order they are written
+var instanceKeys = []string{"source", "checksum", "installed", "updatedFrom", "channel", "java", "lastLaunched", "label", "notes"}
+
+// instanceProperties maps the keys of install.properties to the fields of instanceInfo
+func (info *instanceInfo) instanceProperties() map[string]*string {
+	return map[string]*string{
+		"label":        &info.Label,
+		"source":       &info.Source,
+		"checksum":     &info.Checksum,
+		"installed":    &info.Installed,
+		"updatedFrom":  &info.UpdatedFrom,
+		"channel":      &info.Channel,
+		"java":         &info.Java,
+		"lastLaunched": &info.LastLaunched,
+		"notes":        &info.Notes,
+	}
+}
+
+// loadInstanceProperties reads an install.properties file. Notes are free text, so ${...} is not expanded.
+func loadInstanceProperties(path string) (*properties.Properties, error) {
+	loader := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	return loader.LoadFile(path)
+}
+
+// readInstanceInfo returns what is known of an installation. Installations named after their
+// release may have no install.properties.
+func readInstanceInfo(name string) instanceInfo {
+	info := instanceInfo{}
+	props, err := loadInstanceProperties(filepath.Join(owlcmsInstallDir, name, instanceFileName))
+	if err == nil {
+		info.Version = props.GetString("version", "")
+		fields := info.instanceProperties()
+		for _, key := range instanceKeys {
+			*fields[key] = props.GetString(key, "")
+		}
+		info.Size = props.GetInt64("size", 0)
+	}
+	if info.Version == "" && releaseNamePattern.MatchString(name) {
+		info.Version = name
+	}
+	return info
+}
+
+// writeInstanceInfo saves what is known of an installation, keeping the other entries of the file
+func writeInstanceInfo(name string, info instanceInfo) error {
+	path := filepath.Join(owlcmsInstallDir, name, instanceFileName)
+	props, err := loadInstanceProperties(path)
+	if err != nil {
+		props = properties.NewProperties()
+	}
+	props.Set("version", info.Version)
+	fields := info.instanceProperties()
+	for _, key := range instanceKeys {
+		if *fields[key] != "" {
+			props.Set(key, *fields[key])
+		} else {
+			props.Delete(key)
+		}
+	}
+	if info.Size > 0 {
+		props.Set("size", strconv.FormatInt(info.Size, 10))
+	} else {
+		props.Delete("size")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	defer f.Close()
+	_, err = props.Write(f, properties.UTF8)
+	return err
+}
+
+// recordInstallation writes the origin of a release just extracted in the installation directory folder
+// named name. When it replaces the installation named from, the label and notes of that one are kept.
+func recordInstallation(name, version, source, checksum, from string) {
+	info := instanceInfo{
+		Version:   version,
+		Source:    source,
+		Checksum:  checksum,
+		Installed: time.Now().Format(time.RFC3339),
+		Channel:   "stable",
+		Size:      downloadUtils.DirSize(filepath.Join(owlcmsInstallDir, name)),
+	}
+	if containsPreReleaseTag(version) {
+		info.Channel = "prerelease"
+	}
+	if from != "" {
+		previous := readInstanceInfo(from)
+		info.UpdatedFrom = previous.Version
+		info.Label = previous.Label
+		info.Notes = previous.Notes
+	}
+	if err := writeInstanceInfo(name, info); err != nil {
+		log.Printf("Failed to record the installation of %s: %v\n", version, err)
+	}
+}
+
+// recordLaunch notes the Java runtime and the time of the last launch of an installation
+func recordLaunch(name, javaDescription string) {
+	info := readInstanceInfo(name)
+	info.Java = javaDescription
+	info.LastLaunched = time.Now().Format(time.RFC3339)
+	if err := writeInstanceInfo(name, info); err != nil {
+		log.Printf("Failed to record the launch of %s: %v\n", name, err)
+	}
+}
+
+// hashDownload returns the checksum of a downloaded zip, "" if it cannot be read
+func hashDownload(zipPath string) string {
+	checksum, err := downloadUtils.HashFile(zipPath)
+	if err != nil {
+		log.Printf("Failed to compute the checksum of %s: %v\n", zipPath, err)
+	}
+	return checksum
+}
+
+// instanceVersion returns the owlcms release of an installation, given its directory name
+func instanceVersion(name string) string {
+	return readInstanceInfo(name).Version
+}
+
+// isInstanceDir tells if a directory of the installation directory holds an installed version
+func isInstanceDir(name string) bool {
+	if releaseNamePattern.MatchString(name) {
+		return true
+	}
+	if !instanceNamePattern.MatchString(name) || reservedNamePattern.MatchString(name) {
+		return false
+	}
+	version := instanceVersion(name)
+	_, err := semver.NewVersion(version)
+	return version != "" && err == nil
+}
+
+// instanceTitle is the text identifying an installation in the version list
+func instanceTitle(name string) string {
+	info := readInstanceInfo(name)
+	title := name
+	if info.Version != name {
+		title = fmt.Sprintf("%s (%s)", name, info.Version)
+	}
+	if info.Label != "" {
+		title += " – " + info.Label
+	}
+	return title
+}
+
+// checkInstanceName verifies that a name is valid and free for an installation of version
+func checkInstanceName(name, version string) error {
+	if !instanceNamePattern.MatchString(name) || reservedNamePattern.MatchString(name) || strings.HasPrefix(name, workspaceKeyPrefix) {
+		return fmt.Errorf("%q cannot be used as a name; use letters, digits, spaces, -, _ and .", name)
+	}
+	if releaseNamePattern.MatchString(name) && name != version {
+		return fmt.Errorf("%s is the name of another release", name)
+	}
+	if _, err := os.Stat(filepath.Join(owlcmsInstallDir, name)); err == nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+	return nil
+}
+
+// cloneInstance copies an installation under a new name, with or without its database, so that two
+// platforms can run the same release with separate data
+func cloneInstance(source, name, label string, withData bool) error {
+	if err := checkInstanceName(name, instanceVersion(source)); err != nil {
+		return err
+	}
+	srcDir := filepath.Join(owlcmsInstallDir, source)
+	destDir := filepath.Join(owlcmsInstallDir, name)
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == "database" && !withData {
+			continue
+		}
+		src := filepath.Join(srcDir, entry.Name())
+		dest := filepath.Join(destDir, entry.Name())
+		if entry.IsDir() {
+			err = copyFiles(src, dest, true)
+		} else {
+			err = copyFile(src, dest)
+		}
+		if err != nil {
+			os.RemoveAll(destDir)
+			return fmt.Errorf("copying %s: %w", entry.Name(), err)
+		}
+	}
+	// the copy has the origin of its source, but its own label and history
+	info := readInstanceInfo(source)
+	info.Label = label
+	info.Java = ""
+	info.LastLaunched = ""
+	info.Notes = ""
+	if err := writeInstanceInfo(name, info); err != nil {
+		os.RemoveAll(destDir)
+		return err
+	}
+	log.Printf("Cloned %s to %s\n", source, name)
+	return nil
+}
+
+// renameInstance gives an installation a new directory name. Its backups and the workspaces running it follow.
+func renameInstance(oldName, newName string) error {
+	if err := checkInstanceName(newName, instanceVersion(oldName)); err != nil {
+		return err
+	}
+	if currentProcess != nil && currentVersion == oldName {
+		return fmt.Errorf("stop OWLCMS %s before renaming it", oldName)
+	}
+	// record the release before the name stops telling it
+	info := readInstanceInfo(oldName)
+	if err := writeInstanceInfo(oldName, info); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(owlcmsInstallDir, oldName), filepath.Join(owlcmsInstallDir, newName)); err != nil {
+		return fmt.Errorf("renaming %s: %w", oldName, err)
+	}
+	if _, err := os.Stat(filepath.Join(getBackupDir(), oldName)); err == nil {
+		if err := os.Rename(filepath.Join(getBackupDir(), oldName), filepath.Join(getBackupDir(), newName)); err != nil {
+			log.Printf("Failed to move the backups of %s: %v\n", oldName, err)
+		}
+	}
+	for _, ws := range listWorkspaces() {
+		if ws.Version == oldName {
+			ws.Version = newName
+			if err := writeWorkspace(ws); err != nil {
+				log.Printf("Failed to update workspace %s: %v\n", ws.Name, err)
+			}
+		}
+	}
+	log.Printf("Renamed %s to %s\n", oldName, newName)
+	return nil
+}
+
+// createInstanceMenuButton adds a button opening the Rename, Clone and Label actions of an installation
+func createInstanceMenuButton(version string, w fyne.Window, buttonContainer *fyne.Container) {
+	var menuButton *widget.Button
+	menuButton = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
+		menu := fyne.NewMenu("",
+			fyne.NewMenuItem("Rename...", func() { renameInstanceDialog(version, w) }),
+			fyne.NewMenuItem("Clone...", func() { cloneInstanceDialog(version, w) }),
+			fyne.NewMenuItem("Label...", func() { labelInstanceDialog(version, w) }),
+		)
+		position := fyne.CurrentApp().Driver().AbsolutePositionForObject(menuButton).AddXY(0, menuButton.Size().Height)
+		widget.ShowPopUpMenuAtPosition(menu, w.Canvas(), position)
+	})
+	buttonContainer.Add(container.NewPadded(menuButton))
+}
+
+// renameInstanceDialog asks for the new directory name of an installation
+func renameInstanceDialog(version string, w fyne.Window) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(version)
+	dialog.ShowForm(fmt.Sprintf("Rename %s", version), "Rename", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("New name", nameEntry)},
+		func(ok bool) {
+			name := strings.TrimSpace(nameEntry.Text)
+			if !ok || name == version {
+				return
+			}
+			if err := renameInstance(version, name); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			recomputeVersionList(w)
+		}, w)
+}
+
+// cloneInstanceDialog asks for the name and label of a copy of an installation
+func cloneInstanceDialog(version string, w fyne.Window) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("platform B")
+	labelEntry := widget.NewEntry()
+	withData := widget.NewCheck("Copy the database", nil)
+	dialog.ShowForm(fmt.Sprintf("Clone %s", version), "Clone", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Name", nameEntry),
+			widget.NewFormItem("Label", labelEntry),
+			widget.NewFormItem("", withData),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if withData.Checked && currentProcess != nil && currentVersion == version {
+				dialog.ShowError(fmt.Errorf("stop OWLCMS %s before copying its database", version), w)
+				return
+			}
+			if err := cloneInstance(version, strings.TrimSpace(nameEntry.Text), strings.TrimSpace(labelEntry.Text), withData.Checked); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			recomputeVersionList(w)
+		}, w)
+}
+
+// labelInstanceDialog edits the free text shown next to an installation
+func labelInstanceDialog(version string, w fyne.Window) {
+	info := readInstanceInfo(version)
+	labelEntry := widget.NewEntry()
+	labelEntry.SetText(info.Label)
+	labelEntry.SetPlaceHolder("rehearsal, platform A...")
+	dialog.ShowForm(fmt.Sprintf("Label %s", version), "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Label", labelEntry)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			info.Label = strings.TrimSpace(labelEntry.Text)
+			if err := writeInstanceInfo(version, info); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			recomputeVersionList(w)
+		}, w)
+}
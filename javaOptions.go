@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitCommandLine splits options the way a shell would: on whitespace, with single or double quotes
+// grouping words. A backslash escapes a quote, a backslash or a space; otherwise it is kept as is.
+// The value comes from env.properties, where a single backslash has already been removed when the file
+// was read: Windows paths typed in the file need doubled backslashes (C:\\temp) or forward slashes (C:/temp).
+// The Settings window doubles them when saving.
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && quote != '\'' &&
+			(runes[i+1] == '"' || runes[i+1] == '\'' || runes[i+1] == '\\' || runes[i+1] == ' ' || runes[i+1] == '\t'):
+			current.WriteRune(runes[i+1])
+			inArg = true
+			i++
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// formatCommandLine returns a command as it could be typed, quoting the arguments that contain spaces
+func formatCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			quoted[i] = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		} else {
+			quoted[i] = arg
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// buildJavaArguments returns the arguments given to java: JAVA_OPTIONS before -jar, with a heap size
+// computed from the machine memory if none is given, then PROGRAM_ARGUMENTS after the jar name.
+// The second value describes the heap size for the status area.
+func buildJavaArguments(jarPath, javaOptions, programArgs string) ([]string, string, error) {
+	jvmOptions, err := splitCommandLine(javaOptions)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid JAVA_OPTIONS: %w", err)
+	}
+	programArguments, err := splitCommandLine(programArgs)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid PROGRAM_ARGUMENTS: %w", err)
+	}
+	jvmOptions, heapMessage := adjustHeap(jvmOptions)
+	args := append(jvmOptions, "-jar", jarPath)
+	return append(args, programArguments...), heapMessage, nil
+}
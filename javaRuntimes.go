@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"owlcms-launcher/downloadUtils"
+	"owlcms-launcher/javacheck"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// runtimeUsers returns, for each Java major version, the installed versions that would be launched with it
+func runtimeUsers(runtimes []javacheck.Runtime) map[int][]string {
+	users := map[int][]string{}
+	for _, version := range getAllInstalledVersions() {
+		required := requiredJavaVersion(version)
+		for _, rt := range runtimes {
+			if rt.Major >= required {
+				users[rt.Major] = append(users[rt.Major], version)
+				break
+			}
+		}
+	}
+	return users
+}
+
+// runtimeChangeAllowed refuses to modify runtimes while owlcms or a test upgrade is running, since the files are in use
+func runtimeChangeAllowed(w fyne.Window) bool {
+	if currentProcess != nil {
+		dialog.ShowError(fmt.Errorf("stop OWLCMS %s before changing Java runtimes", currentVersion), w)
+		return false
+	}
+	if sandboxRunning {
+		dialog.ShowError(fmt.Errorf("wait for the test upgrade to finish before changing Java runtimes"), w)
+		return false
+	}
+	return true
+}
+
+// showJavaRuntimes opens a window listing the downloaded Java runtimes, with update and removal actions
+func showJavaRuntimes() {
+	win := fyne.CurrentApp().NewWindow("Java Runtimes")
+	content := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		runtimes := javacheck.InstalledRuntimes()
+		users := runtimeUsers(runtimes)
+		content.RemoveAll()
+
+		if len(runtimes) == 0 {
+			content.Add(widget.NewLabel("No Java runtime has been downloaded. One will be downloaded when a version is launched."))
+		}
+		var unused []javacheck.Runtime
+		for _, rt := range runtimes {
+			rt := rt
+			description := fmt.Sprintf("%s, %s", rt.Release, downloadUtils.FormatSize(downloadUtils.DirSize(rt.Dir)))
+			if rt.Vendor != "" {
+				description = rt.Vendor + " " + description
+			}
+			if versions := users[rt.Major]; len(versions) > 0 {
+				description += "\nUsed by " + strings.Join(versions, ", ")
+			} else {
+				description += "\nNot used by any installed version"
+				unused = append(unused, rt)
+			}
+			if obsolete := javacheck.ObsoleteReleases(rt); len(obsolete) > 0 {
+				description += fmt.Sprintf("\n%d older release(s) left in %s", len(obsolete), rt.Dir)
+			}
+
+			title := widget.NewLabelWithStyle(fmt.Sprintf("Java %d", rt.Major), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			updateButton := widget.NewButton("Check for Update", func() {
+				checkRuntimeUpdate(rt, win, refresh)
+			})
+			removeButton := widget.NewButton("Remove", func() {
+				if !runtimeChangeAllowed(win) {
+					return
+				}
+				message := fmt.Sprintf("Remove Java %d (%s)?", rt.Major, rt.Release)
+				if versions := users[rt.Major]; len(versions) > 0 {
+					message += fmt.Sprintf("\nIt is used by %s and will be downloaded again when needed.", strings.Join(versions, ", "))
+				}
+				dialog.ShowConfirm("Confirm Remove", message, func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := javacheck.RemoveRuntime(rt); err != nil {
+						dialog.ShowError(err, win)
+					}
+					refresh()
+				}, win)
+			})
+			buttons := container.NewHBox(updateButton, removeButton)
+			if len(javacheck.ObsoleteReleases(rt)) > 0 {
+				buttons.Add(widget.NewButton("Clean Up", func() {
+					if !runtimeChangeAllowed(win) {
+						return
+					}
+					if err := javacheck.CleanupRuntime(rt); err != nil {
+						dialog.ShowError(err, win)
+					}
+					refresh()
+				}))
+			}
+			content.Add(container.NewBorder(nil, nil, title, buttons, widget.NewLabel(description)))
+			content.Add(widget.NewSeparator())
+		}
+
+		if len(unused) > 0 {
+			content.Add(container.NewHBox(layout.NewSpacer(), widget.NewButton("Remove Unused Runtimes", func() {
+				if !runtimeChangeAllowed(win) {
+					return
+				}
+				for _, rt := range unused {
+					if err := javacheck.RemoveRuntime(rt); err != nil {
+						dialog.ShowError(err, win)
+						break
+					}
+				}
+				refresh()
+			})))
+		}
+		content.Refresh()
+	}
+	refresh()
+
+	win.SetContent(container.NewVScroll(container.NewPadded(content)))
+	win.Resize(fyne.NewSize(700, 400))
+	win.Show()
+}
+
+// checkRuntimeUpdate looks for a newer patch release of a runtime and offers to install it
+func checkRuntimeUpdate(rt javacheck.Runtime, w fyne.Window, refresh func()) {
+	progressDialog := dialog.NewCustom("Java Runtimes", "Please wait...",
+		widget.NewLabel(fmt.Sprintf("Checking for a newer Java %d release...", rt.Major)), w)
+	progressDialog.Show()
+	go func() {
+		pkg, newer, err := javacheck.FindRuntimeUpdate(rt)
+		progressDialog.Hide()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to check for Java %d updates: %w", rt.Major, err), w)
+			return
+		}
+		if !newer {
+			dialog.ShowInformation("Java Runtimes", fmt.Sprintf("Java %d is up to date (%s).", rt.Major, rt.Release), w)
+			return
+		}
+		message := fmt.Sprintf("Update Java %d from %s to %s (%s)?", rt.Major, rt.Release, pkg.Release, downloadUtils.FormatSize(pkg.Size))
+		if rt.Vendor != "" && rt.Vendor != pkg.Vendor {
+			message = fmt.Sprintf("Replace %s Java %d (%s) with %s %s (%s)?", rt.Vendor, rt.Major, rt.Release, pkg.Vendor, pkg.Release, downloadUtils.FormatSize(pkg.Size))
+		}
+		dialog.ShowConfirm("Update Available", message,
+			func(ok bool) {
+				if !ok || !runtimeChangeAllowed(w) {
+					return
+				}
+				updateDialog := dialog.NewCustom("Java Runtimes", "Please wait...",
+					widget.NewLabel(fmt.Sprintf("Downloading %s...", pkg.Release)), w)
+				updateDialog.Show()
+				go func() {
+					err := javacheck.UpdateRuntime(rt, pkg)
+					updateDialog.Hide()
+					if err != nil {
+						log.Printf("Failed to update Java %d: %v\n", rt.Major, err)
+						dialog.ShowError(fmt.Errorf("failed to update Java %d: %w", rt.Major, err), w)
+					} else {
+						dialog.ShowInformation("Java Runtimes", fmt.Sprintf("Java %d updated to %s.", rt.Major, pkg.Release), w)
+					}
+					refresh()
+				}()
+			}, w)
+	}()
+}
@@ -1,16 +1,12 @@
 package javacheck
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,57 +16,123 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-var owlcmsInstallDir string
+var (
+	owlcmsInstallDir string
+	preferSystemJava bool // use JAVA_HOME or the PATH when they provide a suitable version
+)
 
 func InitJavaCheck(installDir string) {
 	owlcmsInstallDir = installDir
 }
 
-// compareVersions compares two jdk directory names and returns true if a is more recent than b
+// SetPreferSystemJava selects whether a suitable Java from JAVA_HOME or the PATH is used instead of a downloaded one
+func SetPreferSystemJava(prefer bool) {
+	preferSystemJava = prefer
+}
+
+// releaseVersionPattern finds the Java version in a release or directory name: jdk-17.0.13+11-jre (Temurin),
+// zulu17.54.21-ca-jre17.0.13-linux_x64 (Zulu) or jre-17.0.13 (Liberica)
+var releaseVersionPattern = regexp.MustCompile(`(?:jdk|jre)-?(\d+(?:\.\d+)*)(?:\+(\d+))?`)
+
+// releaseVersion returns the version components of a release name and its build number, -1 if absent
+func releaseVersion(name string) ([]int, int) {
+	matches := releaseVersionPattern.FindStringSubmatch(name)
+	if matches == nil {
+		return nil, -1
+	}
+	var parts []int
+	for _, part := range strings.Split(matches[1], ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	build := -1
+	if matches[2] != "" {
+		build, _ = strconv.Atoi(matches[2])
+	}
+	return parts, build
+}
+
+// compareVersions compares two release names and returns true if a is more recent than b.
+// Build numbers are only compared when both names have one, since some vendors omit them from directory names.
 func compareVersions(a, b string) bool {
-	// Extract version numbers from directory names (e.g., "jdk-17.0.9+9" -> "17.0.9")
-	aVersion := strings.TrimPrefix(a, "jdk-")
-	bVersion := strings.TrimPrefix(b, "jdk-")
-
-	// Split into components
-	aParts := strings.Split(strings.Split(aVersion, "+")[0], ".")
-	bParts := strings.Split(strings.Split(bVersion, "+")[0], ".")
-
-	// Compare each component
-	for i := 0; i < len(aParts) && i < len(bParts); i++ {
-		aNum, _ := strconv.Atoi(aParts[i])
-		bNum, _ := strconv.Atoi(bParts[i])
+	aParts, aBuild := releaseVersion(a)
+	bParts, bBuild := releaseVersion(b)
+
+	// Compare each component, a missing one counting as 0 (17 is 17.0.0)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aNum, bNum := 0, 0
+		if i < len(aParts) {
+			aNum = aParts[i]
+		}
+		if i < len(bParts) {
+			bNum = bParts[i]
+		}
 		if aNum != bNum {
 			return aNum > bNum
 		}
 	}
-	return len(aParts) > len(bParts)
+	if aBuild >= 0 && bBuild >= 0 {
+		return aBuild > bBuild
+	}
+	return false
+}
+
+// isReleaseDir returns true if an entry of a java<major> directory holds an extracted runtime
+func isReleaseDir(name string) bool {
+	return strings.HasPrefix(name, "jdk") || strings.HasPrefix(name, "jre") || strings.HasPrefix(name, "zulu")
+}
+
+// javaExecutable returns the path of the java executable of an extracted runtime. On macOS the runtime
+// is a bundle, either at the top (Temurin, Liberica) or inside the extracted directory (Zulu).
+func javaExecutable(releaseDir string) (string, error) {
+	goos := downloadUtils.GetGoos()
+	var candidates []string
+	switch {
+	case goos == "windows" && !isWSL():
+		candidates = []string{filepath.Join(releaseDir, "bin", "javaw.exe")}
+	case goos == "darwin":
+		candidates = []string{filepath.Join(releaseDir, "Contents", "Home", "bin", "java")}
+		bundled, _ := filepath.Glob(filepath.Join(releaseDir, "*.jre", "Contents", "Home", "bin", "java"))
+		candidates = append(candidates, bundled...)
+		candidates = append(candidates, filepath.Join(releaseDir, "bin", "java"))
+	case goos == "linux":
+		candidates = []string{filepath.Join(releaseDir, "bin", "java")}
+	default:
+		log.Printf("*** Unsupported OS: %s\n", goos)
+		return "", fmt.Errorf("unsupported OS: %s", goos)
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	log.Printf("*** Java executable NOT found in %s\n", releaseDir)
+	return "", fmt.Errorf("java executable not found in %s", releaseDir)
 }
 
-func FindLocalJava() (string, error) {
-	javaDir := filepath.Join(owlcmsInstallDir, "java17")
+// findJavaInDir returns the name of the most recent runtime directory in javaDir and the path of its java executable
+func findJavaInDir(javaDir string) (string, string, error) {
 	if _, err := os.Stat(javaDir); err != nil {
 		log.Printf("*** Java directory not found: %v\n", err)
-		return "", fmt.Errorf("java17 directory not found")
+		return "", "", fmt.Errorf("%s directory not found", filepath.Base(javaDir))
 	}
 
 	entries, err := os.ReadDir(javaDir)
 	if err != nil {
 		log.Printf("*** Error reading java directory: %v\n", err)
-		return "", fmt.Errorf("reading java directory: %w", err)
+		return "", "", fmt.Errorf("reading java directory: %w", err)
 	}
 
-	// Find directories starting with "jdk" or "jre"
 	var jdkDirs []string
 	for _, entry := range entries {
-		if entry.IsDir() && (strings.HasPrefix(entry.Name(), "jdk") || strings.HasPrefix(entry.Name(), "jre")) {
+		if entry.IsDir() && isReleaseDir(entry.Name()) {
 			jdkDirs = append(jdkDirs, entry.Name())
 		}
 	}
 
 	if len(jdkDirs) == 0 {
 		log.Printf("*** No Java installation found in %s\n", javaDir)
-		return "", fmt.Errorf("no Java installation found in %s", javaDir)
+		return "", "", fmt.Errorf("no Java installation found in %s", javaDir)
 	}
 
 	// Sort to get the latest version using semantic versioning
@@ -79,73 +141,157 @@ func FindLocalJava() (string, error) {
 	})
 	latestJDK := jdkDirs[0]
 
-	// Check for java executable
-	var javaExe string
-	var javaPath string
-	goos := downloadUtils.GetGoos()
+	javaPath, err := javaExecutable(filepath.Join(javaDir, latestJDK))
+	if err != nil {
+		return "", "", err
+	}
+	return latestJDK, javaPath, nil
+}
 
-	if goos == "windows" && !isWSL() {
-		javaExe = "javaw.exe"
-		javaPath = filepath.Join(javaDir, latestJDK, "bin", javaExe)
-		// log.Printf("*** goos=%s javaPath=%s\n", goos, javaPath)
-	} else if goos == "darwin" {
-		javaExe = "java"
-		javaPath = filepath.Join(javaDir, latestJDK, "Contents", "Home", "bin", javaExe)
-		// log.Printf("*** goos=%s javaPath=%s\n", goos, javaPath)
-	} else if goos == "linux" {
-		javaExe = "java"
-		javaPath = filepath.Join(javaDir, latestJDK, "bin", javaExe)
-		// log.Printf("*** goos=%s javaPath=%s\n", goos, javaPath)
-	} else {
-		log.Printf("*** Unsupported OS: %s\n", goos)
-		return "", fmt.Errorf("unsupported OS: %s", goos)
+// runtimeVendor returns the vendor of a runtime from the IMPLEMENTOR entry of its release file,
+// using the names of JavaVendors when the implementor is one of them
+func runtimeVendor(javaPath string) string {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(javaPath)), "release"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		implementor, found := strings.CutPrefix(strings.TrimSpace(line), "IMPLEMENTOR=")
+		if !found {
+			continue
+		}
+		implementor = strings.Trim(implementor, `"`)
+		switch {
+		case strings.Contains(implementor, "Adoptium"):
+			return TemurinVendor
+		case strings.Contains(implementor, "Azul"):
+			return ZuluVendor
+		case strings.Contains(implementor, "BellSoft"):
+			return LibericaVendor
+		default:
+			return implementor
+		}
 	}
+	return ""
+}
+
+// runtimeDir returns the directory holding the runtime downloaded for a Java major version
+func runtimeDir(major int) string {
+	return filepath.Join(owlcmsInstallDir, fmt.Sprintf("java%d", major))
+}
 
-	_, err = os.Stat(javaPath)
+// InstalledRuntimes lists the usable runtimes downloaded by the launcher, lowest major version first
+func InstalledRuntimes() []Runtime {
+	entries, err := os.ReadDir(owlcmsInstallDir)
 	if err != nil {
-		log.Printf("*** Java executable NOT found in %s: %v\n", javaPath, err)
-		return "", fmt.Errorf("java executable not found in %s: %v", javaPath, err)
-	} else {
-		log.Printf("*** Found local Java %s at: %s\n", latestJDK, javaPath)
-		return javaPath, nil
+		return nil
+	}
+	var runtimes []Runtime
+	for _, entry := range entries {
+		matches := runtimeDirPattern.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || matches == nil {
+			continue
+		}
+		major, _ := strconv.Atoi(matches[1])
+		dir := filepath.Join(owlcmsInstallDir, entry.Name())
+		release, javaPath, err := findJavaInDir(dir)
+		if err != nil {
+			continue
+		}
+		runtimes = append(runtimes, Runtime{Major: major, Dir: dir, Release: release, JavaPath: javaPath, Vendor: runtimeVendor(javaPath)})
+	}
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimes[i].Major < runtimes[j].Major
+	})
+	return runtimes
+}
+
+// findLocalRuntime returns the downloaded runtime best suited to the required major version:
+// the lowest one that is at least the required version.
+func findLocalRuntime(required int) (Runtime, error) {
+	for _, runtime := range InstalledRuntimes() {
+		if runtime.Major >= required {
+			log.Printf("*** Found local Java %s at: %s\n", runtime.Release, runtime.JavaPath)
+			return runtime, nil
+		}
+	}
+	log.Printf("*** No local Java %d or later found\n", required)
+	return Runtime{}, fmt.Errorf("no local Java %d or later found", required)
+}
+
+// FindSystemJava returns the java executable from JAVA_HOME or the PATH if its major version is at least
+// the required one, together with that version
+func FindSystemJava(required int) (string, int, error) {
+	javaPath, err := findJava()
+	if err != nil {
+		return "", 0, err
+	}
+	version, err := getJavaVersion(javaPath)
+	if err != nil {
+		return "", 0, err
 	}
+	if version < required {
+		log.Printf("*** System Java %d at %s is too old, need %d or later\n", version, javaPath, required)
+		return "", version, fmt.Errorf("system Java %d at %s is too old, need %d or later", version, javaPath, required)
+	}
+	log.Printf("*** Found system Java %d at: %s\n", version, javaPath)
+	return javaPath, version, nil
+}
 
+// FindJava returns the java executable to use for the required major version, and a description of it
+// for the user. The system Java is used if it is preferred and suitable, otherwise a downloaded runtime.
+func FindJava(required int) (string, string, error) {
+	if preferSystemJava {
+		javaPath, version, err := FindSystemJava(required)
+		if err == nil {
+			return javaPath, fmt.Sprintf("system Java %d (%s)", version, javaPath), nil
+		}
+		log.Printf("*** System Java not usable, using a downloaded runtime: %v\n", err)
+	}
+	runtime, err := findLocalRuntime(required)
+	if err != nil {
+		return "", "", err
+	}
+	return runtime.JavaPath, fmt.Sprintf("Java %d (%s)", runtime.Major, runtime.Release), nil
 }
 
-// CheckJava checks for Java 17 or later and downloads/installs it if necessary.
-func CheckJava(statusLabel *widget.Label) error {
-	// First check for local Java installation
-	javaPath, err := FindLocalJava()
+// RemoveAllRuntimes deletes the java<major> directories of all the runtimes downloaded by the launcher
+func RemoveAllRuntimes() error {
+	entries, err := os.ReadDir(owlcmsInstallDir)
+	if err != nil {
+		return fmt.Errorf("reading installation directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && runtimeDirPattern.MatchString(entry.Name()) {
+			if err := os.RemoveAll(filepath.Join(owlcmsInstallDir, entry.Name())); err != nil {
+				return fmt.Errorf("removing %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckJava checks for a local Java of the required major version or later and downloads it if necessary.
+func CheckJava(required int, statusLabel *widget.Label) error {
+	// First check for the system Java if preferred, then for a local Java installation
+	javaPath, _, err := FindJava(required)
 	if err == nil {
-		log.Printf("*** Found local Java at: %s\n", javaPath)
+		log.Printf("*** Using Java at: %s\n", javaPath)
 		return nil
 	} else {
-		log.Printf("*** Local Java not found at %s: %v\n", javaPath, err)
-	}
-
-	// // Then check for system Java
-	// javaPath, err = findJava()
-	// if err == nil {
-	// 	version, err := getJavaVersion(javaPath)
-	// 	if err == nil && version >= 17 {
-	// 		log.Printf("System Java %d found at: %s\n", version, javaPath)
-	// 		return nil
-	// 	}
-	// 	if err == nil {
-	// 		log.Printf("System Java version %d is too old, need 17 or later\n", version)
-	// 	}
-	// }
-	fmt.Println("Suitable Java not found. Downloading from Temurin...")
-	statusLabel.SetText("Downloading a local copy of the Java language runtime.")
+		log.Printf("*** Suitable Java not found: %v\n", err)
+	}
+	fmt.Printf("Suitable Java not found. Downloading Java %d from %s...\n", required, javaVendor)
+	statusLabel.SetText(fmt.Sprintf("Downloading a local copy of the Java %d language runtime.", required))
 	statusLabel.Refresh()
 	statusLabel.Show()
 
-	// Recursively delete the java17 directory if it exists
-	javaDir := filepath.Join(owlcmsInstallDir, "java17")
+	// Recursively delete the java directory for this version if it exists
+	javaDir := runtimeDir(required)
 	if _, err := os.Stat(javaDir); err == nil {
 		err := os.RemoveAll(javaDir)
 		if err != nil {
-			return fmt.Errorf("failed to delete existing java17 directory: %w", err)
+			return fmt.Errorf("failed to delete existing %s directory: %w", filepath.Base(javaDir), err)
 		}
 	}
 
@@ -156,52 +302,66 @@ func CheckJava(statusLabel *widget.Label) error {
 		}
 	}
 
-	javaDir = filepath.Join(owlcmsInstallDir, "java17")
 	if _, err := os.Stat(javaDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(javaDir, 0755); err != nil {
 			return fmt.Errorf("creating java directory: %w", err)
 		}
 	}
 
-	url, err := getTemurinDownloadURL()
+	pkg, err := findPackage(required)
 	if err != nil {
-		return fmt.Errorf("getting Temurin download URL: %w", err)
+		return fmt.Errorf("finding a Java %d runtime: %w", required, err)
 	}
+	statusLabel.SetText(fmt.Sprintf("Downloading a local copy of the Java %d language runtime (%s %s, %d MB).", required, pkg.Vendor, pkg.Release, pkg.Size/(1024*1024)))
+	statusLabel.Refresh()
 
-	archivePath := filepath.Join(javaDir, "temurin")
-	if downloadUtils.GetGoos() == "windows" && !isWSL() {
-		archivePath += ".zip"
-	} else {
-		archivePath += ".tar.gz"
+	if err := installPackage(pkg, javaDir); err != nil {
+		return err
 	}
+	// extract now removes the archive
+	log.Printf("Java downloaded and installed to %s\n", javaDir)
+	return nil
+}
+
+// DefaultJavaVersion is the Java major version installed when the requirement of owlcms is not known
+const DefaultJavaVersion = 17
+
+// runtimeDirPattern matches the java<major> directories holding the runtimes downloaded by the launcher
+var runtimeDirPattern = regexp.MustCompile(`^java(\d+)$`)
 
-	if err := downloadUtils.DownloadArchive(url, archivePath); err != nil {
-		return fmt.Errorf("error downloading Temurin: %w", err)
+// Runtime is a Java runtime downloaded by the launcher
+type Runtime struct {
+	Major    int
+	Dir      string // java<major> directory in the installation directory
+	Release  string // name of the runtime directory, e.g. jdk-17.0.13+11-jre
+	JavaPath string
+	Vendor   string // from the release file, empty if unknown
+}
+
+// installPackage downloads a runtime archive into javaDir, checks it against the size and checksum
+// announced by the provider, and extracts it
+func installPackage(pkg *JavaPackage, javaDir string) error {
+	archivePath := filepath.Join(javaDir, pkg.FileName)
+	if err := downloadUtils.DownloadArchive(pkg.URL, archivePath); err != nil {
+		return fmt.Errorf("error downloading %s: %w", pkg.Vendor, err)
+	}
+	if err := downloadUtils.VerifyArchive(archivePath, pkg.Size, pkg.Checksum); err != nil {
+		os.Remove(archivePath)
+		return fmt.Errorf("downloaded %s runtime is damaged: %w", pkg.Vendor, err)
 	}
 
-	if downloadUtils.GetGoos() == "windows" && !isWSL() {
+	if strings.HasSuffix(pkg.FileName, ".zip") {
 		if err := downloadUtils.ExtractZip(archivePath, javaDir); err != nil {
-			return fmt.Errorf("error extracting Temurin zip: %w", err)
+			return fmt.Errorf("error extracting %s zip: %w", pkg.Vendor, err)
 		}
 	} else {
 		if err := downloadUtils.ExtractTarGz(archivePath, javaDir); err != nil {
-			return fmt.Errorf("extracting Temurin tar.gz: %w", err)
+			return fmt.Errorf("extracting %s tar.gz: %w", pkg.Vendor, err)
 		}
 	}
-	// extract now removes the archive
-	log.Printf("Java downloaded and installed to %s\n", javaDir)
 	return nil
 }
 
-type TemurinRelease struct {
-	Name    string `json:"name"`
-	TagName string `json:"tag_name"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-	} `json:"assets"`
-}
-
 // isWSL returns true if running under Windows Subsystem for Linux
 func isWSL() bool {
 	data, err := os.ReadFile("/proc/version")
@@ -211,133 +371,6 @@ func isWSL() bool {
 	return strings.Contains(strings.ToLower(string(data)), "microsoft")
 }
 
-func findLatestTemurinRelease() (string, error) {
-	// Get latest release info from API
-	req, err := http.NewRequest("GET", "https://api.github.com/repos/adoptium/temurin17-binaries/releases/latest", nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "owlcms-launcher")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Failed to fetch latest release: %v", err)
-		return "", fmt.Errorf("failed to fetch latest release: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GitHub API returned status %d: %s\nBody: %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var release TemurinRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		log.Printf("Failed to parse release: %v", err)
-		return "", fmt.Errorf("failed to parse release: %w", err)
-	}
-
-	log.Printf("Latest Temurin release: %s\n", release.TagName)
-	return release.TagName, nil
-}
-
-func getTemurinDownloadURL() (string, error) {
-	// Get the latest release tag
-	tag, err := findLatestTemurinRelease()
-	if err != nil {
-		log.Printf("Failed to get latest version number: %v", err)
-		return "", fmt.Errorf("failed to get latest version number: %w", err)
-	}
-
-	// Extract version number from tag (e.g., "jdk-17.0.13+11" -> "17.0.13_11")
-	version := strings.TrimPrefix(tag, "jdk-")
-	version = strings.ReplaceAll(version, "+", "_")
-
-	// Use the tag to get specific release
-	releaseURL := fmt.Sprintf("https://api.github.com/repos/adoptium/temurin17-binaries/releases/tags/%s", tag)
-
-	req, err := http.NewRequest("GET", releaseURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers required by GitHub API
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "owlcms-launcher")
-
-	// Make the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Failed to fetch release: %v", err)
-		return "", fmt.Errorf("failed to fetch release: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GitHub API returned status %d: %s\nBody: %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var release TemurinRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		log.Printf("Failed to parse release: %v", err)
-		return "", fmt.Errorf("failed to parse release: %w", err)
-	}
-
-	// Print environment info for debugging
-	log.Printf("Running on: OS=%s, ARCH=%s, WSL=%v\n", downloadUtils.GetGoos(), runtime.GOARCH, isWSL())
-
-	// Always use Linux pattern for WSL/Linux, but with correct version
-	var pattern string
-	goos := downloadUtils.GetGoos()
-	if goos == "darwin" {
-		switch runtime.GOARCH {
-		case "amd64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_x64_mac_hotspot_%s.tar.gz", version)
-		case "arm64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_aarch64_mac_hotspot_%s.tar.gz", version)
-		default:
-			return "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
-		}
-	} else if isWSL() || goos == "linux" {
-		switch runtime.GOARCH {
-		case "amd64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_x64_linux_hotspot_%s.tar.gz", version)
-		case "arm64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_aarch64_linux_hotspot_%s.tar.gz", version)
-		default:
-			return "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
-		}
-	} else if goos == "windows" {
-		switch runtime.GOARCH {
-		case "amd64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_x64_windows_hotspot_%s.zip", version)
-		case "arm64":
-			pattern = fmt.Sprintf("OpenJDK17U-jre_aarch64_windows_hotspot_%s.zip", version)
-		default:
-			return "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
-		}
-	} else {
-		return "", fmt.Errorf("unsupported OS: %s", downloadUtils.GetGoos())
-	}
-
-	log.Printf("Looking for asset: %s\n", pattern)
-
-	// Look for exact matching JRE asset
-	for _, asset := range release.Assets {
-		if asset.Name == pattern {
-			log.Printf("Found matching JRE: %s\n", asset.Name)
-			return asset.BrowserDownloadURL, nil
-		}
-	}
-
-	return "", fmt.Errorf("no matching JRE found (looking for %s)", pattern)
-}
-
 func findJava() (string, error) {
 	javaHome := os.Getenv("JAVA_HOME")
 	javaCommand := "java"
@@ -0,0 +1,62 @@
+package javacheck
+
+import (
+	"fmt"
+)
+
+// libericaProvider resolves BellSoft Liberica runtimes with the BellSoft API, which only publishes SHA-1 checksums
+type libericaProvider struct{}
+
+type libericaRelease struct {
+	Version     string `json:"version"` // e.g. 17.0.13+12
+	DownloadURL string `json:"downloadUrl"`
+	Filename    string `json:"filename"`
+	SHA1        string `json:"sha1"`
+	Size        int64  `json:"size"`
+}
+
+func (libericaProvider) Name() string {
+	return "BellSoft API"
+}
+
+func (libericaProvider) FindPackage(major int) (*JavaPackage, error) {
+	javaOS, err := javaOS()
+	if err != nil {
+		return nil, err
+	}
+	arch, err := javaArch()
+	if err != nil {
+		return nil, err
+	}
+	// BellSoft gives the processor family and the bitness separately
+	libericaOS := map[string]string{"linux": "linux", "mac": "macos", "windows": "windows"}[javaOS]
+	libericaArch, bitness := "x86", 64
+	switch arch {
+	case "aarch64":
+		libericaArch = "arm"
+	case "arm":
+		libericaArch, bitness = "arm", 32
+	}
+
+	url := fmt.Sprintf("https://api.bell-sw.com/v1/liberica/releases?version-feature=%d&version-modifier=latest&os=%s&arch=%s&bitness=%d"+
+		"&bundle-type=jre&package-type=%s&installation-type=archive",
+		major, libericaOS, libericaArch, bitness, archiveExtension())
+	var releases []libericaRelease
+	if err := getJSON(url, &releases); err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.DownloadURL == "" {
+			continue
+		}
+		return &JavaPackage{
+			Vendor:   LibericaVendor,
+			Release:  "jre-" + release.Version,
+			URL:      release.DownloadURL,
+			FileName: release.Filename,
+			Checksum: release.SHA1,
+			Size:     release.Size,
+		}, nil
+	}
+	return nil, fmt.Errorf("no Liberica %d JRE for %s/%s %d-bit", major, libericaOS, libericaArch, bitness)
+}
@@ -0,0 +1,151 @@
+package javacheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+)
+
+// JavaPackage is a downloadable Java runtime archive
+type JavaPackage struct {
+	Vendor   string
+	Release  string // release name, e.g. jdk-17.0.13+11, compared with compareVersions
+	URL      string
+	FileName string // archive name, its extension tells how to extract it
+	Checksum string // hexadecimal SHA-256 (SHA-1 for Liberica), empty if the provider does not publish one
+	Size     int64  // archive size in bytes, 0 if unknown
+}
+
+// RuntimeProvider finds the Java runtime archive matching the current OS and architecture
+type RuntimeProvider interface {
+	Name() string
+	FindPackage(major int) (*JavaPackage, error)
+}
+
+// Java vendors whose runtimes can be downloaded. Some organizations only allow specific vendors.
+const (
+	TemurinVendor  = "Temurin"
+	ZuluVendor     = "Zulu"
+	LibericaVendor = "Liberica"
+)
+
+// JavaVendors lists the vendors in the order shown to the user, the default first
+var JavaVendors = []string{TemurinVendor, ZuluVendor, LibericaVendor}
+
+// vendorProviders are tried in order until one of them finds a package of the selected vendor
+var vendorProviders = map[string][]RuntimeProvider{
+	TemurinVendor:  {adoptiumProvider{}, githubTemurinProvider{}},
+	ZuluVendor:     {zuluProvider{}},
+	LibericaVendor: {libericaProvider{}},
+}
+
+// javaVendor is the vendor used for new downloads
+var javaVendor = TemurinVendor
+
+// SetJavaVendor selects the vendor of the runtimes downloaded from now on. An empty name selects the default.
+func SetJavaVendor(vendor string) error {
+	if vendor == "" {
+		javaVendor = TemurinVendor
+		return nil
+	}
+	for _, known := range JavaVendors {
+		if strings.EqualFold(vendor, known) {
+			javaVendor = known
+			return nil
+		}
+	}
+	javaVendor = TemurinVendor
+	return fmt.Errorf("unknown Java vendor %s, expected one of %s", vendor, strings.Join(JavaVendors, ", "))
+}
+
+// JavaVendor returns the vendor used for new downloads
+func JavaVendor() string {
+	return javaVendor
+}
+
+// findPackage asks each provider of the selected vendor in turn for a runtime of the given major version
+func findPackage(major int) (*JavaPackage, error) {
+	var errs []string
+	for _, provider := range vendorProviders[javaVendor] {
+		pkg, err := provider.FindPackage(major)
+		if err == nil {
+			log.Printf("%s provides %s (%s, %d bytes)\n", provider.Name(), pkg.FileName, pkg.Release, pkg.Size)
+			return pkg, nil
+		}
+		log.Printf("%s could not provide Java %d: %v\n", provider.Name(), major, err)
+		errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), err))
+	}
+	return nil, fmt.Errorf("no %s Java %d runtime found for %s/%s (%s)", javaVendor, major, downloadUtils.GetGoos(), runtime.GOARCH, strings.Join(errs, "; "))
+}
+
+// archiveExtension returns the archive format offered by the vendors for the current OS
+func archiveExtension() string {
+	if javaOS, _ := javaOS(); javaOS == "windows" {
+		return "zip"
+	}
+	return "tar.gz"
+}
+
+// javaOS returns the operating system name used by Java vendors: linux, windows or mac.
+// WSL uses Linux binaries.
+func javaOS() (string, error) {
+	goos := downloadUtils.GetGoos()
+	switch {
+	case goos == "darwin":
+		return "mac", nil
+	case goos == "linux" || isWSL():
+		return "linux", nil
+	case goos == "windows":
+		return "windows", nil
+	default:
+		return "", fmt.Errorf("unsupported OS: %s", goos)
+	}
+}
+
+// javaArch returns the architecture name used by Java vendors. 32-bit ARM is the one found on
+// older Raspberry Pi and is built for armv7.
+func javaArch() (string, error) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x64", nil
+	case "arm64":
+		return "aarch64", nil
+	case "arm":
+		return "arm", nil
+	default:
+		return "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	}
+}
+
+// getJSON performs a GET request and decodes the JSON response into target
+func getJSON(url string, target interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "owlcms-launcher")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned status %s: %s", url, resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+	return nil
+}
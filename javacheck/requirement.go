@@ -0,0 +1,101 @@
+package javacheck
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// classFileOffset is the number added to a Java major version to obtain the class file major version (61 is Java 17)
+const classFileOffset = 44
+
+// RequiredJavaVersion returns the Java major version needed to run a jar. The class file version of the
+// main class is authoritative; the Build-Jdk-Spec entry of the manifest is used if the class cannot be read.
+func RequiredJavaVersion(jarPath string) (int, error) {
+	r, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return 0, fmt.Errorf("opening %s: %w", jarPath, err)
+	}
+	defer r.Close()
+
+	manifest, err := readManifest(&r.Reader)
+	if err != nil {
+		return 0, err
+	}
+
+	// Spring Boot jars launch Start-Class from BOOT-INF/classes, plain jars launch Main-Class
+	var candidates []string
+	if startClass := manifest["Start-Class"]; startClass != "" {
+		candidates = append(candidates, "BOOT-INF/classes/"+strings.ReplaceAll(startClass, ".", "/")+".class")
+	}
+	if mainClass := manifest["Main-Class"]; mainClass != "" {
+		candidates = append(candidates, strings.ReplaceAll(mainClass, ".", "/")+".class")
+	}
+	for _, candidate := range candidates {
+		if major, err := classFileJavaVersion(&r.Reader, candidate); err == nil {
+			log.Printf("%s requires Java %d (class file version of %s)\n", jarPath, major, candidate)
+			return major, nil
+		}
+	}
+
+	if spec := manifest["Build-Jdk-Spec"]; spec != "" {
+		major, err := strconv.Atoi(strings.TrimPrefix(spec, "1."))
+		if err == nil {
+			log.Printf("%s requires Java %d (Build-Jdk-Spec)\n", jarPath, major)
+			return major, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot determine the Java version required by %s", jarPath)
+}
+
+// readManifest returns the main attributes of META-INF/MANIFEST.MF
+func readManifest(r *zip.Reader) (map[string]string, error) {
+	f, err := r.Open("META-INF/MANIFEST.MF")
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	defer f.Close()
+
+	attributes := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	lastKey := ""
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break // end of the main section
+		}
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			// continuation of a long value
+			attributes[lastKey] += line[1:]
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			lastKey = strings.TrimSpace(key)
+			attributes[lastKey] = strings.TrimSpace(value)
+		}
+	}
+	return attributes, scanner.Err()
+}
+
+// classFileJavaVersion reads the class file header of an entry and converts its major version to a Java version
+func classFileJavaVersion(r *zip.Reader, name string) (int, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, err
+	}
+	if binary.BigEndian.Uint32(header[0:4]) != 0xCAFEBABE {
+		return 0, fmt.Errorf("%s is not a class file", name)
+	}
+	return int(binary.BigEndian.Uint16(header[6:8])) - classFileOffset, nil
+}
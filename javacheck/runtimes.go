@@ -0,0 +1,99 @@
+package javacheck
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ObsoleteReleases returns the runtime directories of a java<major> directory that are older than the one in use.
+// They accumulate when several archives have been extracted in the same java<major> directory.
+func ObsoleteReleases(rt Runtime) []string {
+	entries, err := os.ReadDir(rt.Dir)
+	if err != nil {
+		return nil
+	}
+	var obsolete []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && name != rt.Release && isReleaseDir(name) {
+			obsolete = append(obsolete, filepath.Join(rt.Dir, name))
+		}
+	}
+	sort.Strings(obsolete)
+	return obsolete
+}
+
+// CleanupRuntime removes the obsolete releases of a runtime, keeping the most recent one
+func CleanupRuntime(rt Runtime) error {
+	for _, dir := range ObsoleteReleases(rt) {
+		log.Printf("Removing obsolete Java release %s\n", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("removing %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// FindRuntimeUpdate returns the most recent package of the selected vendor for the major version of a runtime,
+// and whether it should replace the installed release: it is newer, or the runtime comes from another vendor
+func FindRuntimeUpdate(rt Runtime) (*JavaPackage, bool, error) {
+	pkg, err := findPackage(rt.Major)
+	if err != nil {
+		return nil, false, err
+	}
+	if rt.Vendor != "" && rt.Vendor != pkg.Vendor {
+		return pkg, true, nil
+	}
+	return pkg, compareVersions(pkg.Release, rt.Release), nil
+}
+
+// UpdateRuntime replaces a runtime by the given package. The package is extracted next to the current
+// runtime and only swapped in once it has been checked, so a failed download leaves the runtime intact.
+// It must not be called while a server uses the runtime.
+func UpdateRuntime(rt Runtime, pkg *JavaPackage) error {
+	stagingDir := rt.Dir + ".new"
+	previousDir := rt.Dir + ".old"
+	os.RemoveAll(stagingDir)
+	os.RemoveAll(previousDir)
+
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", stagingDir, err)
+	}
+	if err := installPackage(pkg, stagingDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return err
+	}
+	if _, _, err := findJavaInDir(stagingDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("downloaded runtime is not usable: %w", err)
+	}
+
+	if err := os.Rename(rt.Dir, previousDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("moving current runtime aside: %w", err)
+	}
+	if err := os.Rename(stagingDir, rt.Dir); err != nil {
+		// put the previous runtime back
+		if restoreErr := os.Rename(previousDir, rt.Dir); restoreErr != nil {
+			log.Printf("Failed to restore %s: %v\n", rt.Dir, restoreErr)
+		}
+		return fmt.Errorf("installing updated runtime: %w", err)
+	}
+	if err := os.RemoveAll(previousDir); err != nil {
+		log.Printf("Failed to remove previous runtime %s: %v\n", previousDir, err)
+	}
+	log.Printf("Updated Java %d from %s to %s\n", rt.Major, rt.Release, pkg.Release)
+	return nil
+}
+
+// RemoveRuntime deletes a downloaded runtime
+func RemoveRuntime(rt Runtime) error {
+	if err := os.RemoveAll(rt.Dir); err != nil {
+		return fmt.Errorf("removing %s: %w", rt.Dir, err)
+	}
+	log.Printf("Removed Java %d (%s)\n", rt.Major, rt.Release)
+	return nil
+}
@@ -0,0 +1,160 @@
+package javacheck
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adoptiumProvider resolves Temurin runtimes with the Adoptium API, which reports the checksum and size
+// and is not subject to the GitHub rate limits
+type adoptiumProvider struct{}
+
+type adoptiumAsset struct {
+	Binary struct {
+		Package struct {
+			Name     string `json:"name"`
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"`
+			Size     int64  `json:"size"`
+		} `json:"package"`
+	} `json:"binary"`
+	ReleaseName string `json:"release_name"`
+}
+
+func (adoptiumProvider) Name() string {
+	return "Adoptium API"
+}
+
+func (adoptiumProvider) FindPackage(major int) (*JavaPackage, error) {
+	javaOS, err := javaOS()
+	if err != nil {
+		return nil, err
+	}
+	arch, err := javaArch()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://api.adoptium.net/v3/assets/latest/%d/hotspot?architecture=%s&image_type=jre&os=%s&vendor=eclipse", major, arch, javaOS)
+	var assets []adoptiumAsset
+	if err := getJSON(url, &assets); err != nil {
+		return nil, err
+	}
+	for _, asset := range assets {
+		pkg := asset.Binary.Package
+		if pkg.Link == "" || !(strings.HasSuffix(pkg.Name, ".zip") || strings.HasSuffix(pkg.Name, ".tar.gz")) {
+			continue
+		}
+		return &JavaPackage{
+			Vendor:   TemurinVendor,
+			Release:  asset.ReleaseName,
+			URL:      pkg.Link,
+			FileName: pkg.Name,
+			Checksum: pkg.Checksum,
+			Size:     pkg.Size,
+		}, nil
+	}
+	return nil, fmt.Errorf("no Temurin %d JRE for %s/%s", major, javaOS, arch)
+}
+
+// githubTemurinProvider resolves Temurin runtimes from the releases of the temurinNN-binaries repositories
+type githubTemurinProvider struct{}
+
+type TemurinRelease struct {
+	Name    string `json:"name"`
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+		Size               int64  `json:"size"`
+	} `json:"assets"`
+}
+
+func (githubTemurinProvider) Name() string {
+	return "GitHub Temurin releases"
+}
+
+func (githubTemurinProvider) FindPackage(major int) (*JavaPackage, error) {
+	var release TemurinRelease
+	url := fmt.Sprintf("https://api.github.com/repos/adoptium/temurin%d-binaries/releases/latest", major)
+	if err := getJSON(url, &release); err != nil {
+		return nil, err
+	}
+	log.Printf("Latest Temurin release: %s\n", release.TagName)
+
+	pattern, err := getTemurinAssetName(major, release.TagName)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Looking for asset: %s\n", pattern)
+
+	// Look for exact matching JRE asset, and the checksum published next to it
+	var pkg *JavaPackage
+	checksumURL := ""
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case pattern:
+			pkg = &JavaPackage{
+				Vendor:   TemurinVendor,
+				Release:  release.TagName,
+				URL:      asset.BrowserDownloadURL,
+				FileName: asset.Name,
+				Size:     asset.Size,
+			}
+		case pattern + ".sha256.txt":
+			checksumURL = asset.BrowserDownloadURL
+		}
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("no matching JRE found (looking for %s)", pattern)
+	}
+	if checksumURL != "" {
+		checksum, err := fetchChecksum(checksumURL)
+		if err != nil {
+			log.Printf("Failed to get checksum for %s: %v\n", pattern, err)
+		}
+		pkg.Checksum = checksum
+	}
+	return pkg, nil
+}
+
+// getTemurinAssetName returns the name of the JRE archive in a Temurin release
+// (e.g. "jdk-17.0.13+11" -> "OpenJDK17U-jre_x64_linux_hotspot_17.0.13_11.tar.gz")
+func getTemurinAssetName(major int, tag string) (string, error) {
+	javaOS, err := javaOS()
+	if err != nil {
+		return "", err
+	}
+	arch, err := javaArch()
+	if err != nil {
+		return "", err
+	}
+	version := strings.ReplaceAll(strings.TrimPrefix(tag, "jdk-"), "+", "_")
+	return fmt.Sprintf("OpenJDK%dU-jre_%s_%s_hotspot_%s.%s", major, arch, javaOS, version, archiveExtension()), nil
+}
+
+// fetchChecksum reads a checksum file in the "<sha256>  <file name>" format
+func fetchChecksum(url string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned status %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file %s", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
@@ -0,0 +1,66 @@
+package javacheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zuluProvider resolves Azul Zulu runtimes with the Azul metadata API. The package list does not include
+// the checksum, which is read from the details of the chosen package.
+type zuluProvider struct{}
+
+type zuluPackage struct {
+	PackageUUID string `json:"package_uuid"`
+	Name        string `json:"name"`
+	DownloadURL string `json:"download_url"`
+	JavaVersion []int  `json:"java_version"`
+}
+
+type zuluPackageDetails struct {
+	SHA256Hash string `json:"sha256_hash"`
+	Size       int64  `json:"size"`
+}
+
+func (zuluProvider) Name() string {
+	return "Azul metadata API"
+}
+
+func (zuluProvider) FindPackage(major int) (*JavaPackage, error) {
+	javaOS, err := javaOS()
+	if err != nil {
+		return nil, err
+	}
+	arch, err := javaArch()
+	if err != nil {
+		return nil, err
+	}
+	// Azul names differ from the Adoptium ones; glibc excludes the Alpine builds
+	zuluOS := map[string]string{"linux": "linux-glibc", "mac": "macos", "windows": "windows"}[javaOS]
+	zuluArch := map[string]string{"x64": "x64", "aarch64": "aarch64", "arm": "aarch32hf"}[arch]
+
+	url := fmt.Sprintf("https://api.azul.com/metadata/v1/zulu/packages/?java_version=%d&os=%s&arch=%s&archive_type=%s"+
+		"&java_package_type=jre&javafx_bundled=false&crac_supported=false&release_status=ga&availability_types=CA&latest=true&page_size=10",
+		major, zuluOS, zuluArch, archiveExtension())
+	var packages []zuluPackage
+	if err := getJSON(url, &packages); err != nil {
+		return nil, err
+	}
+	for _, p := range packages {
+		if p.DownloadURL == "" || !strings.HasSuffix(p.Name, "."+archiveExtension()) {
+			continue
+		}
+		var details zuluPackageDetails
+		if err := getJSON("https://api.azul.com/metadata/v1/zulu/packages/"+p.PackageUUID, &details); err != nil {
+			return nil, fmt.Errorf("reading checksum of %s: %w", p.Name, err)
+		}
+		return &JavaPackage{
+			Vendor:   ZuluVendor,
+			Release:  strings.TrimSuffix(p.Name, "."+archiveExtension()), // same as the extracted directory
+			URL:      p.DownloadURL,
+			FileName: p.Name,
+			Checksum: details.SHA256Hash,
+			Size:     details.Size,
+		}, nil
+	}
+	return nil, fmt.Errorf("no Zulu %d JRE for %s/%s", major, zuluOS, zuluArch)
+}
@@ -21,8 +21,9 @@ import (
 var (
 	lockFilePath = filepath.Join(owlcmsInstallDir, "java.lock")
 	pidFilePath  = filepath.Join(owlcmsInstallDir, "java.pid")
-	javaPID      int          // Add a global variable to store the Java process PID
-	lock         *flock.Flock // Add a global variable to store the lock
+	javaPID      int           // Add a global variable to store the Java process PID
+	lock         *flock.Flock  // Add a global variable to store the lock
+	processDone  chan struct{} // closed once the server has exited and its data has been backed up
 )
 
 func acquireJavaLock() (*flock.Flock, error) {
@@ -98,6 +99,17 @@ func killLockingProcess() error {
 	return nil
 }
 
+// requiredJavaVersion returns the Java major version needed by an installed version
+func requiredJavaVersion(version string) int {
+	jarPath := filepath.Join(owlcmsInstallDir, version, "owlcms.jar")
+	required, err := javacheck.RequiredJavaVersion(jarPath)
+	if err != nil {
+		log.Printf("Using Java %d for %s: %v\n", javacheck.DefaultJavaVersion, version, err)
+		return javacheck.DefaultJavaVersion
+	}
+	return max(required, javacheck.DefaultJavaVersion)
+}
+
 func launchOwlcms(version string, launchButton, stopButton *widget.Button) error {
 	currentVersion = version // Store current version
 
@@ -110,6 +122,27 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 		return err
 	}
 
+	// Load env.properties and the selected profile, which may change the port
+	if selectedProfile != "" {
+		if _, err := os.Stat(getProfileFilePath(selectedProfile)); err != nil {
+			goBackToMainScreen()
+			return fmt.Errorf("profile %s not found", selectedProfile)
+		}
+	}
+	if selectedWorkspace != "" {
+		if _, err := os.Stat(getWorkspaceDir(selectedWorkspace)); err != nil {
+			goBackToMainScreen()
+			return fmt.Errorf("workspace %s not found", selectedWorkspace)
+		}
+	}
+	InitVersionEnv(version)
+	activeProfile = selectedProfile
+	activeWorkspace = selectedWorkspace
+	dataKey := version // the data of a workspace lives in its own folder, that of a version in the version folder
+	if activeWorkspace != "" {
+		dataKey = workspaceKey(activeWorkspace)
+	}
+
 	// Check if port is already in use
 	if err := checkPort(); err == nil {
 		statusLabel.SetText(fmt.Sprintf("Another program is running on port %s", GetPort()))
@@ -119,7 +152,7 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 		return fmt.Errorf("another program is running on port %s", GetPort())
 	}
 
-	statusLabel.SetText(fmt.Sprintf("Starting OWLCMS %s...", version))
+	statusLabel.SetText(fmt.Sprintf("Starting OWLCMS %s%s...", version, profileDescription()))
 	statusLabel.Refresh()
 	statusLabel.Show() // Show the status label when starting Java
 	// Store current directory to restore it later
@@ -143,15 +176,19 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 		return fmt.Errorf("owlcms.jar not found in %s directory", jarPath)
 	}
 
-	// Change to version directory
-	if err := os.Chdir(filepath.Join(owlcmsDir, version)); err != nil {
+	// Change to the directory holding the database and local folders; owlcms uses those of its working directory
+	if err := os.Chdir(getDataDir(dataKey)); err != nil {
 		launchButton.Show() // Show launch button again if start fails
-		return fmt.Errorf("changing to version directory: %w", err)
+		return fmt.Errorf("changing to data directory: %w", err)
 	}
 	defer os.Chdir(originalDir)
+	jarArg := "owlcms.jar"
+	if activeWorkspace != "" {
+		jarArg = jarPath
+	}
 
 	// find the java runtime binary
-	localJava, err := javacheck.FindLocalJava()
+	localJava, javaDescription, err := javacheck.FindJava(requiredJavaVersion(version))
 	if err != nil {
 		statusLabel.SetText(fmt.Sprintf("Failed to find local Java: %v", err))
 		launchButton.Show()
@@ -159,7 +196,6 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 		return fmt.Errorf("failed to find local Java: %w", err)
 	}
 
-	InitEnv()
 	env := os.Environ()
 	env = append(env, fmt.Sprintf("OWLCMS_LAUNCHER=%s", version))
 
@@ -176,10 +212,18 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 	// 	log.Printf("  %s", envVar)
 	// }
 
-	// Start the Java process
-	cmd := exec.Command(localJava, "-jar", "owlcms.jar")
+	// Start the Java process, with the JVM options before -jar
+	javaArgs, heapMessage, err := buildJavaArguments(jarArg, GetSetting("JAVA_OPTIONS", ""), GetSetting("PROGRAM_ARGUMENTS", ""))
+	if err != nil {
+		statusLabel.SetText(err.Error())
+		launchButton.Show()
+		goBackToMainScreen()
+		return err
+	}
+	cmd := exec.Command(localJava, javaArgs...)
 	cmd.Env = env
-	log.Printf("Starting OWLCMS %s with command: %v\n", version, cmd.Args)
+	commandLine := formatCommandLine(cmd.Args)
+	log.Printf("Starting OWLCMS %s with command: %s\n", version, commandLine)
 	if err := cmd.Start(); err != nil {
 		statusLabel.SetText(fmt.Sprintf("Failed to start OWLCMS %s", version))
 		releaseJavaLock()
@@ -198,19 +242,23 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 	}
 
 	log.Printf("Launching OWLCMS %s (PID: %d), waiting for port %s...\n", version, javaPID, GetPort())
-	statusLabel.SetText(fmt.Sprintf("Starting OWLCMS %s (PID: %d), waiting for port %s.\nFull startup can take up to 30 seconds.", version, javaPID, GetPort()))
+	statusLabel.SetText(fmt.Sprintf("Starting OWLCMS %s%s (PID: %d), waiting for port %s.\nFull startup can take up to 30 seconds.\nUsing %s\n%s\n%s", version, profileDescription(), javaPID, GetPort(), javaDescription, heapMessage, commandLine))
 	currentProcess = cmd
-	stopButton.SetText(fmt.Sprintf("Stop OWLCMS %s", version))
+	recordLaunch(version, javaDescription)
+	stopButton.SetText(fmt.Sprintf("Stop OWLCMS %s%s", version, profileDescription()))
 	stopButton.Show()
 	stopContainer.Show()
 	downloadContainer.Hide()
 	versionContainer.Hide()
 
 	// Monitor the process in background
-	monitorChan := monitorProcess(cmd)
+	monitorChan, exitChan := monitorProcess(cmd, GetPort())
+	done := make(chan struct{})
+	processDone = done
 
 	// Wait for monitoring result in background
 	go func() {
+		defer close(done)
 		if err := <-monitorChan; err != nil {
 			log.Printf("OWLCMS process %d failed to start properly: %v\n", javaPID, err)
 			statusLabel.SetText(fmt.Sprintf("OWLCMS process %d failed to start properly", javaPID))
@@ -225,15 +273,39 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 		}
 
 		log.Printf("OWLCMS process %d is ready (port %s responding)\n", javaPID, GetPort())
-		statusLabel.SetText(fmt.Sprintf("OWLCMS running (PID: %d) on port %s", javaPID, GetPort()))
+		status := fmt.Sprintf("OWLCMS %s running%s (PID: %d) on port %s", version, profileDescription(), javaPID, GetPort())
+		status += "\nUsing " + javaDescription
+		if heapMessage != "" {
+			status += "\n" + heapMessage
+		}
+		if mdnsURL, err := advertiseOwlcms(version); err != nil {
+			log.Printf("Failed to advertise OWLCMS on the local network: %v\n", err)
+		} else if mdnsURL != "" {
+			status += fmt.Sprintf("\nDisplays can connect to %s", mdnsURL)
+		}
+		if secureURL, err := startHTTPSProxy(); err != nil {
+			log.Printf("Failed to start HTTPS front-end: %v\n", err)
+			status += fmt.Sprintf("\nHTTPS could not be started on port %s", getHTTPSPort())
+		} else if secureURL != "" {
+			status += fmt.Sprintf("\nSecure access (camera, microphone): %s", secureURL)
+		}
+		if interval := startScheduledBackups(dataKey, func(message string) {
+			statusLabel.SetText(status + "\n" + message)
+		}); interval > 0 {
+			status += fmt.Sprintf("\nDatabase backed up every %s", interval)
+		}
+		statusLabel.SetText(status)
 		url := fmt.Sprintf("http://localhost:%s", GetPort())
 		urlLink.SetURLFromString(url)
 		urlLink.SetText("Open OWLCMS in a browser")
 		urlLink.Show()
 
 		// Process is stable, wait for it to end
-		err := cmd.Wait()
+		err := <-exitChan
 		pid := cmd.Process.Pid
+		withdrawOwlcms()
+		stopHTTPSProxy()
+		stopScheduledBackups()
 
 		if killedByUs {
 			// If we killed it, just report normal termination
@@ -248,7 +320,17 @@ func launchOwlcms(version string, launchButton, stopButton *widget.Button) error
 			statusLabel.SetText(fmt.Sprintf("OWLCMS %s (PID: %d) exited normally", version, pid))
 		}
 
+		// The database is closed now, keep a copy in case a later update or import goes wrong
+		if backupPath, err := backupVersion(dataKey, "stop"); err != nil {
+			log.Printf("Failed to back up OWLCMS %s: %v\n", version, err)
+			statusLabel.SetText(statusLabel.Text + fmt.Sprintf("\nBackup failed: %v", err))
+		} else if backupPath != "" {
+			statusLabel.SetText(statusLabel.Text + fmt.Sprintf("\nData backed up to %s", backupPath))
+		}
+
 		currentProcess = nil
+		activeProfile = ""
+		activeWorkspace = ""
 		killedByUs = false // Reset flag
 		stopButton.Hide()
 		stopContainer.Hide()
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"owlcms-launcher/downloadUtils"
+)
+
+// localChange is a file of the local folder that differs from the release
+type localChange struct {
+	Path  string // slash-separated, relative to the version directory, e.g. local/templates/x.xlsx
+	Added bool   // true if the file is not part of the release, false if the release file was modified
+}
+
+// modifiedLocalFiles compares the local folder of an installed version with the manifest written when it was
+// extracted, and returns the files that were added or changed since. It fails if the version has no manifest,
+// which is the case for the versions installed before manifests were introduced.
+func modifiedLocalFiles(versionDir string) ([]localChange, error) {
+	manifest, err := downloadUtils.ReadManifest(filepath.Join(versionDir, downloadUtils.ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("no manifest of the release files: %w", err)
+	}
+	var changes []localChange
+	err = filepath.Walk(filepath.Join(versionDir, "local"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(versionDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		pristine, known := manifest[relPath]
+		if !known {
+			changes = append(changes, localChange{Path: relPath, Added: true})
+			return nil
+		}
+		hash, err := downloadUtils.HashFile(path)
+		if err != nil {
+			return err
+		}
+		if hash != pristine {
+			changes = append(changes, localChange{Path: relPath})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// changedLocalFiles returns the local files added or modified in a version. Versions without a manifest
+// fall back to the files modified after the local folder itself.
+func changedLocalFiles(versionDir string) ([]localChange, error) {
+	changes, err := modifiedLocalFiles(versionDir)
+	if err == nil {
+		return changes, nil
+	}
+	log.Printf("Using modification times to find the local files of %s: %v\n", versionDir, err)
+	localDir := filepath.Join(versionDir, "local")
+	dirInfo, err := os.Stat(localDir)
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.ModTime().Before(dirInfo.ModTime()) {
+			return err
+		}
+		relPath, err := filepath.Rel(versionDir, path)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, localChange{Path: filepath.ToSlash(relPath)})
+		return nil
+	})
+	return changes, err
+}
+
+// fileMerge is a customized local file whose release copy also changed, and whose changes conflict.
+// Until the conflicts are resolved, the destination keeps the customized file.
+type fileMerge struct {
+	Path  string // slash-separated, relative to the version directory
+	Hunks []mergeHunk
+}
+
+// mergeLocalFile merges the customizations of a local file of srcVersionDir into the release copy of the same
+// file in destVersionDir, using the release copy of srcVersionDir as common ancestor. Both release copies come
+// from the pristine archives. ok is false when there is nothing to merge: a version without pristine archive,
+// a file absent from the other release or unchanged in it, or a binary file.
+func mergeLocalFile(srcVersionDir, destVersionDir, relPath string) (hunks []mergeHunk, ok bool) {
+	base, err := downloadUtils.ReadZipEntry(filepath.Join(srcVersionDir, downloadUtils.PristineArchiveName), relPath)
+	if err != nil {
+		return nil, false
+	}
+	theirs, err := downloadUtils.ReadZipEntry(filepath.Join(destVersionDir, downloadUtils.PristineArchiveName), relPath)
+	if err != nil || bytes.Equal(base, theirs) {
+		return nil, false
+	}
+	mine, err := os.ReadFile(filepath.Join(srcVersionDir, filepath.FromSlash(relPath)))
+	if err != nil || !isText(base) || !isText(mine) || !isText(theirs) {
+		return nil, false
+	}
+	return mergeLines(splitLines(string(base)), splitLines(string(mine)), splitLines(string(theirs)))
+}
+
+// carryLocalFile copies a customized local file to another version. When the other release changed the file
+// too, the two sets of changes are merged; if they conflict, the customized file is copied and the conflicts
+// are returned for the user to resolve.
+func carryLocalFile(srcVersionDir, destVersionDir, relPath string) (*fileMerge, error) {
+	src := filepath.Join(srcVersionDir, filepath.FromSlash(relPath))
+	dest := filepath.Join(destVersionDir, filepath.FromSlash(relPath))
+	hunks, ok := mergeLocalFile(srcVersionDir, destVersionDir, relPath)
+	if ok && !hasConflicts(hunks) {
+		log.Printf("Merging the changes of %s with those of the release in %s\n", src, dest)
+		return nil, os.WriteFile(dest, []byte(joinMerge(hunks, nil)), 0644)
+	}
+	log.Printf("Copying file: %s to %s\n", src, dest)
+	if err := copyFile(src, dest); err != nil {
+		return nil, err
+	}
+	if ok {
+		log.Printf("Customizations of %s conflict with the changes of the release\n", relPath)
+		return &fileMerge{Path: relPath, Hunks: hunks}, nil
+	}
+	return nil, nil
+}
+
+// copyLocalFiles copies the files of the local folder that the user added or modified to another version,
+// and returns the files whose merge with the other release has conflicts
+func copyLocalFiles(srcVersionDir, destVersionDir string) ([]*fileMerge, error) {
+	changes, err := changedLocalFiles(srcVersionDir)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []*fileMerge
+	for _, change := range changes {
+		conflict, err := carryLocalFile(srcVersionDir, destVersionDir, change.Path)
+		if err != nil {
+			return conflicts, err
+		}
+		if conflict != nil {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts, nil
+}
+
+// copyFile copies a file, creating the destination directory and keeping the permissions and modification time
+func copyFile(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	destFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destFile, srcFile); err != nil {
+		destFile.Close()
+		return err
+	}
+	if err := destFile.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, info.ModTime(), info.ModTime())
+}
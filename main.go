@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
@@ -9,7 +10,6 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"sync"
 
 	"owlcms-launcher/downloadUtils"
 	"owlcms-launcher/javacheck"
@@ -75,7 +75,7 @@ func getInstallDir() string {
 	}
 }
 
-func checkJava(statusLabel *widget.Label) error {
+func checkJava(required int, statusLabel *widget.Label) error {
 	statusLabel.SetText("Checking for the Java language runtime.")
 	statusLabel.Refresh()
 	statusLabel.Show()
@@ -84,7 +84,7 @@ func checkJava(statusLabel *widget.Label) error {
 	versionContainer.Hide()
 	downloadContainer.Hide()
 
-	err := javacheck.CheckJava(statusLabel)
+	err := javacheck.CheckJava(required, statusLabel)
 	if err != nil {
 		statusLabel.SetText("Could not install a Java runtime.")
 		statusLabel.Refresh()
@@ -121,8 +121,7 @@ func removeAllVersions() {
 
 	for _, entry := range entries {
 		if entry.IsDir() {
-			_, err := semver.NewVersion(entry.Name())
-			if err == nil {
+			if isInstanceDir(entry.Name()) {
 				dirPath := filepath.Join(owlcmsInstallDir, entry.Name())
 				if err := os.RemoveAll(dirPath); err != nil {
 					log.Printf("Failed to remove directory %s: %v\n", dirPath, err)
@@ -160,8 +159,10 @@ func uninstallAll() {
 }
 
 func removeJava() {
-	javaDir := filepath.Join(owlcmsInstallDir, "java17")
-	err := os.RemoveAll(javaDir)
+	if !runtimeChangeAllowed(fyne.CurrentApp().Driver().AllWindows()[0]) {
+		return
+	}
+	err := javacheck.RemoveAllRuntimes()
 	if err != nil {
 		log.Printf("Failed to remove Java: %v\n", err)
 		dialog.ShowError(fmt.Errorf("failed to remove Java: %w", err), fyne.CurrentApp().Driver().AllWindows()[0])
@@ -173,7 +174,16 @@ func removeJava() {
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	flag.StringVar(&selectedProfile, "profile", "", "name of the environment profile (env-<name>.properties) used when launching")
+	flag.Parse()
+	// the name becomes part of a file path, so it follows the same rule as the names entered in the UI
+	if selectedProfile != "" && !profilePattern.MatchString(selectedProfile) {
+		log.Fatalf("Invalid profile name %q: use letters, digits, spaces, - and _", selectedProfile)
+	}
 	log.Println("Starting OWLCMS Launcher")
+	if selectedProfile != "" {
+		log.Printf("Profile %s selected on the command line\n", selectedProfile)
+	}
 	a := app.NewWithID("app.owlcms.owlcms-launcher")
 	a.Settings().SetTheme(newMyTheme())
 	w := a.NewWindow("OWLCMS Control Panel")
@@ -228,7 +238,11 @@ func main() {
 
 	var javaAvailable bool
 	go func() {
-		javaLoc, err := javacheck.FindLocalJava()
+		InitEnv()
+		if message := recoverUpdate(); message != "" {
+			dialog.ShowInformation("Update Recovery", message, w)
+		}
+		javaLoc, _, err := javacheck.FindJava(javacheck.DefaultJavaVersion)
 		javaAvailable = err == nil && javaLoc != ""
 
 		// Check for internet connection before anything else
@@ -236,7 +250,7 @@ func main() {
 		// log.Printf("javaloc %s err %v javaAvailable %t internetAvailable %t", javaLoc, err, javaAvailable, internetAvailable)
 		if internetAvailable && !javaAvailable {
 			// Check for Java before anything else
-			if err := checkJava(statusLabel); err != nil {
+			if err := checkJava(javacheck.DefaultJavaVersion, statusLabel); err != nil {
 				dialog.ShowError(fmt.Errorf("failed to fetch Java: %w", err), w)
 			}
 		}
@@ -295,15 +309,43 @@ func main() {
 
 		// Create menu items
 		fileMenu := fyne.NewMenu("File",
+			fyne.NewMenuItem("Settings", func() {
+				showSettingsWindow(getEnvFilePath(), "Settings")
+			}),
+			fyne.NewMenuItem("New Environment Profile", func() {
+				createProfile(w)
+			}),
+			fyne.NewMenuItem("Edit Environment Profile", func() {
+				showProfileChooser(w)
+			}),
+			fyne.NewMenuItem("Workspaces", func() {
+				showWorkspacesWindow()
+			}),
+			fyne.NewMenuItem("Backups", func() {
+				showBackupsWindow()
+			}),
+			fyne.NewMenuItem("Compare Configurations", func() {
+				showCompareWindow()
+			}),
+			fyne.NewMenuItem("Storage", func() {
+				showStorageWindow(w)
+			}),
+			fyne.NewMenuItemSeparator(),
 			fyne.NewMenuItem("Remove All Versions", func() {
 				removeAllVersions()
 			}),
+			fyne.NewMenuItem("Java Runtimes", func() {
+				showJavaRuntimes()
+			}),
 			fyne.NewMenuItem("Remove Java", func() {
 				removeJava()
 			}),
 			fyne.NewMenuItem("Remove All Stored Data and Configurations", func() {
 				uninstallAll()
 			}),
+			fyne.NewMenuItem("Save HTTPS Certificate Authority", func() {
+				exportCACertificate(w)
+			}),
 			fyne.NewMenuItem("Open Installation Directory", func() {
 				if err := openFileExplorer(owlcmsInstallDir); err != nil {
 					dialog.ShowError(fmt.Errorf("failed to open installation directory: %w", err), w)
@@ -373,8 +415,13 @@ func main() {
 					func(confirm bool) {
 						if !confirm {
 							log.Println("Closing OWLCMS Launcher")
-							stopProcess(currentProcess, currentVersion, stopButton, downloadContainer, versionContainer, statusLabel, w)
-							w.Close()
+							// wait for the backup taken when the server stops
+							go func() {
+								if err := stopServerAndWait(w); err != nil {
+									log.Printf("Closing anyway: %v\n", err)
+								}
+								w.Close()
+							}()
 						}
 					},
 					w,
@@ -395,18 +442,15 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 
-	var wg sync.WaitGroup
-
 	// Goroutine to handle interrupt signal
 	go func() {
 		<-sigChan
 		log.Println("Interrupt signal caught, stopping Java process...")
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			stopProcess(currentProcess, currentVersion, stopButton, downloadContainer, versionContainer, statusLabel, w)
-		}()
-		wg.Wait()
+		if currentProcess != nil {
+			if err := stopServerAndWait(w); err != nil {
+				log.Printf("Exiting anyway: %v\n", err)
+			}
+		}
 		log.Println("Exiting Control Panel...")
 		os.Exit(0)
 	}()
@@ -461,6 +505,8 @@ func downloadAndInstallVersion(version string, w fyne.Window) {
 
 	go func() {
 		// Download the ZIP file using downloadUtils
+		markDownloading(zipPath, true)
+		defer markDownloading(zipPath, false)
 		log.Printf("Starting download from URL: %s\n", zipURL)
 		err := downloadUtils.DownloadArchive(zipURL, zipPath)
 		if err != nil {
@@ -469,14 +515,17 @@ func downloadAndInstallVersion(version string, w fyne.Window) {
 			return
 		}
 
+		checksum := hashDownload(zipPath)
+
 		// Extract the ZIP file to version-specific subdirectory
 		log.Printf("Extracting ZIP file to: %s\n", extractPath)
-		err = downloadUtils.ExtractZip(zipPath, extractPath)
+		err = downloadUtils.ExtractZipWithManifest(zipPath, extractPath)
 		if err != nil {
 			progressDialog.Hide()
 			dialog.ShowError(fmt.Errorf("extraction failed: %w", err), w)
 			return
 		}
+		recordInstallation(version, version, zipURL, checksum, "")
 
 		// Log when extraction is done
 		log.Println("Extraction completed")
@@ -500,16 +549,10 @@ func downloadAndInstallVersion(version string, w fyne.Window) {
 
 		// Recompute the downloadTitle
 		checkForNewerVersion()
+		retentionAfterInstall(version, w)
 	}()
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 func checkForNewerVersion() {
 	latestInstalled := findLatestInstalled()
 
@@ -611,8 +654,8 @@ func updateExplanation() {
 		// Remove the label from the container first
 		downloadContainer.Remove(singleOrMultiVersionLabel)
 
-		if containsPreReleaseTag(x[0]) {
-			if preErr == nil && x[0] == latestPrerelease {
+		if containsPreReleaseTag(instanceVersion(x[0])) {
+			if preErr == nil && instanceVersion(x[0]) == latestPrerelease {
 				// It's the latest prerelease; do not re-insert the label
 			} else {
 				// Not the latest; re-insert singleOrMultiVersionLabel as second item
@@ -620,7 +663,7 @@ func updateExplanation() {
 				singleOrMultiVersionLabel.SetText("Use the Update button above to install the latest version. The current database will be copied to the new version, as well as local changes made to the configuration since the previous installation.")
 			}
 		} else {
-			if stableErr == nil && x[0] == latestStable {
+			if stableErr == nil && instanceVersion(x[0]) == latestStable {
 				// It's the latest stable; do not re-insert the label
 			} else {
 				downloadContainer.Objects = append(downloadContainer.Objects[:1], append([]fyne.CanvasObject{singleOrMultiVersionLabel}, downloadContainer.Objects[1:]...)...)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/hashicorp/mdns"
+)
+
+var mdnsServer *mdns.Server // non-nil while owlcms is advertised on the local network
+
+// getLocalIPs returns the IPv4 addresses of the network interfaces that are up, excluding loopback
+func getLocalIPs() []net.IP {
+	var ips []net.IP
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		log.Printf("Failed to list network interfaces: %v\n", err)
+		return ips
+	}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				ips = append(ips, ipNet.IP)
+			}
+		}
+	}
+	return ips
+}
+
+// advertiseOwlcms registers the running server as an _http._tcp service so that
+// displays can reach it as http://owlcms.local instead of typing an IP address.
+// It does nothing unless OWLCMS_LAUNCHER_MDNS=true is set in env.properties.
+func advertiseOwlcms(version string) (string, error) {
+	if !GetBoolSetting("OWLCMS_LAUNCHER_MDNS") {
+		return "", nil
+	}
+	withdrawOwlcms()
+
+	port, err := strconv.Atoi(GetPort())
+	if err != nil {
+		return "", fmt.Errorf("invalid port %s: %w", GetPort(), err)
+	}
+	ips := getLocalIPs()
+	if len(ips) == 0 {
+		return "", fmt.Errorf("no network interface available for mDNS")
+	}
+
+	name := GetSetting("OWLCMS_LAUNCHER_MDNSNAME", "owlcms")
+	hostName := name + ".local."
+	txt := []string{
+		"version=" + version,
+		"port=" + GetPort(),
+		"path=/",
+	}
+	service, err := mdns.NewMDNSService(name, "_http._tcp", "local.", hostName, port, ips, txt)
+	if err != nil {
+		return "", fmt.Errorf("creating mDNS service: %w", err)
+	}
+	server, err := mdns.NewServer(&mdns.Config{Zone: service})
+	if err != nil {
+		return "", fmt.Errorf("starting mDNS server: %w", err)
+	}
+	mdnsServer = server
+
+	url := fmt.Sprintf("http://%s.local:%d", name, port)
+	log.Printf("Advertising OWLCMS %s as %s on %v\n", version, url, ips)
+	return url, nil
+}
+
+// withdrawOwlcms stops answering mDNS queries for the server
+func withdrawOwlcms() {
+	if mdnsServer == nil {
+		return
+	}
+	if err := mdnsServer.Shutdown(); err != nil {
+		log.Printf("Failed to stop mDNS server: %v\n", err)
+	}
+	mdnsServer = nil
+	log.Println("Stopped advertising OWLCMS on the local network")
+}
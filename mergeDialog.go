@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Choices offered for each conflict of a merge
+const (
+	keepMine           = "Mine"
+	keepRelease        = "Release"
+	keepMineAndRelease = "Both"
+)
+
+// showMergeConflicts shows, side by side, the customizations and the release changes that conflict in the
+// files of a version, and writes the merge chosen for each conflict. The files keep the customized text
+// until the choices are applied.
+func showMergeConflicts(version string, merges []*fileMerge) {
+	win := fyne.CurrentApp().NewWindow(fmt.Sprintf("Merge Conflicts in %s", version))
+	versionDir := filepath.Join(owlcmsInstallDir, version)
+
+	// choices[i][c] is the choice for conflict c of merges[i]
+	choices := make([][]string, len(merges))
+	paths := make([]string, len(merges))
+	for i, m := range merges {
+		paths[i] = m.Path
+		for _, h := range m.Hunks {
+			if h.Conflict {
+				choices[i] = append(choices[i], keepMine)
+			}
+		}
+	}
+
+	conflictsBox := container.NewVBox()
+	fileSelect := widget.NewSelect(paths, nil)
+	fileSelect.OnChanged = func(string) {
+		i := fileSelect.SelectedIndex()
+		conflictsBox.RemoveAll()
+		conflict := 0
+		for _, h := range merges[i].Hunks {
+			if !h.Conflict {
+				continue
+			}
+			c := conflict
+			conflict++
+			choice := widget.NewRadioGroup([]string{keepMine, keepRelease, keepMineAndRelease}, func(selected string) {
+				if selected != "" {
+					choices[i][c] = selected
+				}
+			})
+			choice.Horizontal = true
+			choice.Required = true
+			choice.SetSelected(choices[i][c])
+			sides := container.NewGridWithColumns(2,
+				widget.NewCard("", "Mine", conflictText(h.Mine)),
+				widget.NewCard("", fmt.Sprintf("Release %s", version), conflictText(h.Theirs)))
+			conflictsBox.Add(container.NewBorder(widget.NewLabel(fmt.Sprintf("Conflict %d", c+1)), choice, nil, nil, sides))
+			conflictsBox.Add(widget.NewSeparator())
+		}
+		conflictsBox.Refresh()
+	}
+
+	applyButton := widget.NewButton("Apply Choices", func() {
+		for i, m := range merges {
+			resolved := joinMerge(m.Hunks, func(c int, h mergeHunk) []string {
+				switch choices[i][c] {
+				case keepRelease:
+					return h.Theirs
+				case keepMineAndRelease:
+					return append(append([]string{}, h.Mine...), h.Theirs...)
+				default:
+					return h.Mine
+				}
+			})
+			path := filepath.Join(versionDir, filepath.FromSlash(m.Path))
+			if err := os.WriteFile(path, []byte(resolved), 0644); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to write %s: %w", m.Path, err), win)
+				return
+			}
+			log.Printf("Resolved the merge conflicts of %s\n", path)
+		}
+		win.Close()
+	})
+	applyButton.Importance = widget.HighImportance
+	keepButton := widget.NewButton("Keep My Files", func() {
+		win.Close()
+	})
+
+	header := widget.NewLabel(fmt.Sprintf("These files of %s were customized, and the new release changed the same lines.\n"+
+		"Until the choices are applied, the files keep your text; applying them also brings the other changes of the release.", version))
+	top := container.NewVBox(header, container.NewBorder(nil, nil, widget.NewLabel("File"), nil, fileSelect))
+	win.SetContent(container.NewBorder(top, container.NewHBox(layout.NewSpacer(), keepButton, applyButton), nil, nil,
+		container.NewVScroll(conflictsBox)))
+	fileSelect.SetSelectedIndex(0)
+	win.Resize(fyne.NewSize(900, 600))
+	win.Show()
+}
+
+// conflictText shows the lines of one side of a conflict
+func conflictText(lines []string) fyne.CanvasObject {
+	text := strings.Join(lines, "")
+	if text == "" {
+		text = "(removed)"
+	}
+	label := widget.NewLabel(strings.TrimSuffix(text, "\n"))
+	label.TextStyle = fyne.TextStyle{Monospace: true}
+	label.Wrapping = fyne.TextWrapBreak
+	return label
+}
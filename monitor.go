@@ -19,7 +19,12 @@ var killedByUs bool
 
 // checkPort tries to connect to localhost:port and returns nil if successful
 func checkPort() error {
-	resp, err := http.Get(fmt.Sprintf("http://localhost:%s", GetPort()))
+	return checkPortAt(GetPort())
+}
+
+// checkPortAt tries to connect to the given local port and returns nil if successful
+func checkPortAt(port string) error {
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%s", port))
 	if err != nil {
 		return err
 	}
@@ -27,16 +32,22 @@ func checkPort() error {
 	return nil
 }
 
-func monitorProcess(cmd *exec.Cmd) chan error {
+// monitorProcess waits for a started process to answer on port. The first channel receives nil once the
+// port responds, or an error if the process exits or times out first. The second receives the result of
+// cmd.Wait when the process ends, since Wait can only be called once.
+func monitorProcess(cmd *exec.Cmd, port string) (chan error, chan error) {
 	result := make(chan error, 1)
+	exited := make(chan error, 1)
 	go func() {
 		// Start a goroutine to wait for process exit
 		done := make(chan error, 1)
 		go func() {
-			done <- cmd.Wait()
+			err := cmd.Wait()
+			done <- err
+			exited <- err
 		}()
 
-		// Try connecting to port 8080 for up to 60 seconds
+		// Try connecting to the port for up to 60 seconds
 		timeout := time.After(60 * time.Second)
 		ticker := time.NewTicker(500 * time.Millisecond)
 		defer ticker.Stop()
@@ -55,7 +66,7 @@ func monitorProcess(cmd *exec.Cmd) chan error {
 				result <- fmt.Errorf("timed out waiting for process to become ready")
 				return
 			case <-ticker.C:
-				if err := checkPort(); err == nil {
+				if err := checkPortAt(port); err == nil {
 					// Port is responding, process is ready
 					result <- nil
 					return
@@ -63,7 +74,7 @@ func monitorProcess(cmd *exec.Cmd) chan error {
 			}
 		}
 	}()
-	return result
+	return result, exited
 }
 
 func stopProcess(currentProcess *exec.Cmd, currentVersion string, stopButton *widget.Button, downloadGroup, versionContainer *fyne.Container, statusLabel *widget.Label, w fyne.Window) {
@@ -75,6 +86,9 @@ func stopProcess(currentProcess *exec.Cmd, currentVersion string, stopButton *wi
 	}
 	pid := currentProcess.Process.Pid
 	killedByUs = true
+	withdrawOwlcms()
+	stopHTTPSProxy()
+	stopScheduledBackups()
 
 	var err error
 	if downloadUtils.GetGoos() == "windows" {
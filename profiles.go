@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const defaultProfile = "(default)" // shown in the profile dropdowns when no profile is layered over env.properties
+
+var (
+	selectedProfile string // profile chosen with --profile or in the version list, "" for env.properties alone
+	activeProfile   string // profile used by the running server
+	profilePattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _-]*$`)
+)
+
+// getProfileFilePath returns the file holding the variables of a named profile
+func getProfileFilePath(profile string) string {
+	return filepath.Join(owlcmsInstallDir, fmt.Sprintf("env-%s.properties", profile))
+}
+
+// getProfiles returns the names of the env-<name>.properties files found in the installation directory
+func getProfiles() []string {
+	entries, err := os.ReadDir(owlcmsInstallDir)
+	if err != nil {
+		return nil
+	}
+	var profiles []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "env-") || !strings.HasSuffix(name, ".properties") {
+			continue
+		}
+		profile := strings.TrimSuffix(strings.TrimPrefix(name, "env-"), ".properties")
+		if profilePattern.MatchString(profile) {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// profileDescription returns the text used in status messages for the workspace and profile of the running server
+func profileDescription() string {
+	description := ""
+	if activeWorkspace != "" {
+		description += fmt.Sprintf(" [workspace %s]", activeWorkspace)
+	}
+	if activeProfile != "" {
+		description += fmt.Sprintf(" [profile %s]", activeProfile)
+	}
+	return description
+}
+
+// createProfileSelect adds a dropdown to choose the profile used by the Launch button that follows it.
+// The dropdown is only shown when at least one profile exists.
+func createProfileSelect(buttonContainer *fyne.Container) *widget.Select {
+	profiles := getProfiles()
+	if len(profiles) == 0 {
+		return nil
+	}
+	profileSelect := widget.NewSelect(append([]string{defaultProfile}, profiles...), func(selected string) {
+		if selected == defaultProfile {
+			selectedProfile = ""
+		} else {
+			selectedProfile = selected
+		}
+	})
+	if selectedProfile != "" {
+		profileSelect.SetSelected(selectedProfile)
+	} else {
+		profileSelect.SetSelected(defaultProfile)
+	}
+	buttonContainer.Add(profileSelect)
+	return profileSelect
+}
+
+// createProfile asks for a profile name, creates env-<name>.properties and opens it in the settings editor
+func createProfile(w fyne.Window) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("competition")
+	nameEntry.Validator = func(name string) error {
+		if !profilePattern.MatchString(name) {
+			return fmt.Errorf("use letters, digits, spaces, - and _")
+		}
+		return nil
+	}
+	dialog.ShowForm("New Environment Profile", "Create", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Profile name", nameEntry),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			profile := strings.TrimSpace(nameEntry.Text)
+			profilePath := getProfileFilePath(profile)
+			if _, err := os.Stat(profilePath); err == nil {
+				dialog.ShowError(fmt.Errorf("profile %s already exists", profile), w)
+				return
+			}
+			header := fmt.Sprintf("# Profile %s: these variables are applied over those in env.properties\n", profile)
+			if err := os.WriteFile(profilePath, []byte(header), 0644); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to create profile %s: %w", profile, err), w)
+				return
+			}
+			log.Printf("Created profile %s in %s\n", profile, profilePath)
+			recomputeVersionList(w)
+			editProfile(profile)
+		}, w)
+}
+
+// editProfile opens a profile in the settings editor
+func editProfile(profile string) {
+	showSettingsWindow(getProfileFilePath(profile), fmt.Sprintf("Profile %s", profile))
+}
+
+// showProfileChooser lets the user pick the existing profile to edit
+func showProfileChooser(w fyne.Window) {
+	profiles := getProfiles()
+	if len(profiles) == 0 {
+		dialog.ShowInformation("No Profiles", "Use \"New Environment Profile\" to create one.", w)
+		return
+	}
+	profileSelect := widget.NewSelect(profiles, nil)
+	profileSelect.SetSelected(profiles[0])
+	dialog.ShowForm("Edit Environment Profile", "Edit", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Profile", profileSelect),
+		},
+		func(ok bool) {
+			if ok && profileSelect.Selected != "" {
+				editProfile(profileSelect.Selected)
+			}
+		}, w)
+}
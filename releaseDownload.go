@@ -170,6 +170,8 @@ func createReleaseDropdown(w fyne.Window) (*widget.Select, *fyne.Container) {
 
 				go func() {
 					// Download the ZIP file using downloadUtils
+					markDownloading(zipPath, true)
+					defer markDownloading(zipPath, false)
 					log.Printf("Starting download from URL: %s\n", zipURL)
 					err := downloadUtils.DownloadArchive(zipURL, zipPath)
 					if err != nil {
@@ -178,14 +180,17 @@ func createReleaseDropdown(w fyne.Window) (*widget.Select, *fyne.Container) {
 						return
 					}
 
+					checksum := hashDownload(zipPath)
+
 					// Extract the ZIP file to version-specific subdirectory
 					log.Printf("Extracting ZIP file to: %s\n", extractPath)
-					err = downloadUtils.ExtractZip(zipPath, extractPath)
+					err = downloadUtils.ExtractZipWithManifest(zipPath, extractPath)
 					if err != nil {
 						progressDialog.Hide()
 						dialog.ShowError(fmt.Errorf("extraction failed: %w", err), w)
 						return
 					}
+					recordInstallation(selected, selected, zipURL, checksum, "")
 
 					// Log when extraction is done
 					log.Println("Extraction completed")
@@ -211,6 +216,7 @@ func createReleaseDropdown(w fyne.Window) (*widget.Select, *fyne.Container) {
 
 					// Recompute the downloadTitle
 					checkForNewerVersion()
+					retentionAfterInstall(selected, w)
 				}()
 			},
 			w)
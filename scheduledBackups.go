@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+)
+
+// stableCopyAttempts is how many times the database is copied before giving up when it keeps changing
+const stableCopyAttempts = 5
+
+var (
+	scheduledBackupStop  chan struct{} // closed to end the scheduled backups of the running server
+	scheduledBackupMutex sync.Mutex    // the server can be stopped from the UI and from its monitoring goroutine
+)
+
+// getBackupInterval returns the interval between backups of the running server, 0 if they are disabled
+func getBackupInterval() time.Duration {
+	value := GetSetting("OWLCMS_LAUNCHER_BACKUPINTERVAL", "")
+	if value == "" {
+		return 0
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		log.Printf("Invalid OWLCMS_LAUNCHER_BACKUPINTERVAL %q, scheduled backups disabled\n", value)
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// startScheduledBackups backs up the database of the running version at the configured interval.
+// report is called with a line describing the outcome of each backup, for the status area.
+// It returns the interval, 0 if scheduled backups are disabled.
+func startScheduledBackups(version string, report func(string)) time.Duration {
+	stopScheduledBackups()
+	interval := getBackupInterval()
+	if interval == 0 {
+		return 0
+	}
+	mirror := GetSetting("OWLCMS_LAUNCHER_BACKUPMIRROR", "")
+	stop := make(chan struct{})
+	scheduledBackupMutex.Lock()
+	scheduledBackupStop = stop
+	scheduledBackupMutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				now := time.Now().Format("15:04")
+				zipPath, err := backupRunningVersion(version)
+				if err == nil && mirror != "" {
+					err = mirrorBackup(zipPath, mirror, version)
+				}
+				if err != nil {
+					log.Printf("Scheduled backup of %s failed: %v\n", version, err)
+					report(fmt.Sprintf("Scheduled backup failed at %s: %v", now, err))
+				} else {
+					report(fmt.Sprintf("Last scheduled backup at %s", now))
+				}
+			}
+		}
+	}()
+	log.Printf("Backing up %s every %s\n", version, interval)
+	return interval
+}
+
+// stopScheduledBackups ends the scheduled backups, if any
+func stopScheduledBackups() {
+	scheduledBackupMutex.Lock()
+	defer scheduledBackupMutex.Unlock()
+	if scheduledBackupStop != nil {
+		close(scheduledBackupStop)
+		scheduledBackupStop = nil
+	}
+}
+
+// backupRunningVersion backs up the database of a version while the server writes to it. The files are
+// first copied to a staging folder, and the copy is only zipped if no file changed while it was made.
+func backupRunningVersion(version string) (string, error) {
+	databaseDir := filepath.Join(getDataDir(version), "database")
+	if _, err := os.Stat(databaseDir); err != nil {
+		return "", fmt.Errorf("no database for %s: %w", version, err)
+	}
+	dir := filepath.Join(getBackupDir(), version)
+	stagingDir := filepath.Join(dir, ".staging")
+	defer os.RemoveAll(stagingDir)
+
+	stable := false
+	for attempt := 1; attempt <= stableCopyAttempts && !stable; attempt++ {
+		os.RemoveAll(stagingDir)
+		before, err := databaseSnapshot(databaseDir)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Join(stagingDir, "database"), 0755); err != nil {
+			return "", fmt.Errorf("creating %s: %w", stagingDir, err)
+		}
+		if err := copyFiles(databaseDir, filepath.Join(stagingDir, "database"), true); err != nil {
+			return "", fmt.Errorf("copying the database: %w", err)
+		}
+		after, err := databaseSnapshot(databaseDir)
+		if err != nil {
+			return "", err
+		}
+		stable = before == after
+		if !stable {
+			log.Printf("Database of %s changed during copy attempt %d\n", version, attempt)
+			time.Sleep(time.Second)
+		}
+	}
+	if !stable {
+		return "", fmt.Errorf("the database kept changing during %d copy attempts", stableCopyAttempts)
+	}
+
+	zipPath := filepath.Join(dir, fmt.Sprintf("%s_scheduled.zip", time.Now().Format(backupTimeFormat)))
+	if err := downloadUtils.CreateZip(zipPath, stagingDir, "database"); err != nil {
+		return "", err
+	}
+	log.Printf("Backed up the running %s to %s\n", version, zipPath)
+	if err := pruneBackups(getBackupDir(), version, getBackupKeep()); err != nil {
+		log.Printf("Failed to remove old backups of %s: %v\n", version, err)
+	}
+	return zipPath, nil
+}
+
+// databaseSnapshot summarizes the names, sizes and modification times of the database files,
+// to detect writes made while they are copied
+func databaseSnapshot(databaseDir string) (string, error) {
+	snapshot := ""
+	err := filepath.Walk(databaseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			snapshot += fmt.Sprintf("%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading the database folder: %w", err)
+	}
+	return snapshot, nil
+}
+
+// mirrorBackup copies a backup archive to a second folder, such as a USB drive, with the same retention
+func mirrorBackup(zipPath, mirror, version string) error {
+	dir := filepath.Join(mirror, version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("copy to %s failed: %w", mirror, err)
+	}
+	src, err := os.Open(zipPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	destPath := filepath.Join(dir, filepath.Base(zipPath))
+	dest, err := os.Create(destPath + ".part")
+	if err != nil {
+		return fmt.Errorf("copy to %s failed: %w", mirror, err)
+	}
+	_, err = io.Copy(dest, src)
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(destPath+".part", destPath)
+	}
+	if err != nil {
+		os.Remove(destPath + ".part")
+		return fmt.Errorf("copy to %s failed: %w", mirror, err)
+	}
+	if err := pruneBackups(mirror, version, getBackupKeep()); err != nil {
+		log.Printf("Failed to remove old backups of %s from %s: %v\n", version, mirror, err)
+	}
+	return nil
+}
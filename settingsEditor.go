@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/magiconair/properties"
+)
+
+const notSet = "(not set)"
+
+// extraSettingRow is a free-form variable that is not part of envSchema
+type extraSettingRow struct {
+	key     *widget.Entry
+	value   *widget.Entry
+	warning *widget.Label
+	row     *fyne.Container
+}
+
+// showSettingsWindow opens an editor for env.properties or a profile. Known variables get a widget matching
+// their type, other variables are edited as free-form key/value pairs. Comments are preserved when saving.
+func showSettingsWindow(envFilePath string, title string) {
+	InitEnv()
+	props, header, trailing, err := readEnvFile(envFilePath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to read %s: %w", envFilePath, err), fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+	win := fyne.CurrentApp().NewWindow(title)
+
+	// known variables
+	getters := map[string]func() string{}
+	owlcmsForm := widget.NewForm()
+	launcherForm := widget.NewForm()
+	for i := range envSchema {
+		setting := &envSchema[i]
+		value, _ := props.Get(setting.Key)
+		var input fyne.CanvasObject
+		switch setting.Kind {
+		case boolSetting, choiceSetting:
+			options := []string{notSet}
+			if setting.Kind == boolSetting {
+				options = append(options, "true", "false")
+			} else {
+				options = append(options, setting.Choices...)
+			}
+			selector := widget.NewSelect(options, nil)
+			selector.SetSelected(notSet)
+			if value != "" {
+				if setting.validate(value) != nil {
+					// keep values we do not know about instead of silently dropping them
+					selector.Options = append(selector.Options, value)
+				}
+				selector.SetSelected(value)
+			}
+			getters[setting.Key] = func() string {
+				if selector.Selected == notSet {
+					return ""
+				}
+				return selector.Selected
+			}
+			input = selector
+		default:
+			entry := widget.NewEntry()
+			entry.SetText(value)
+			entry.Validator = setting.validate
+			getters[setting.Key] = func() string { return strings.TrimSpace(entry.Text) }
+			input = entry
+		}
+		item := widget.NewFormItem(setting.Key, input)
+		item.HintText = setting.Description
+		if setting.Launcher {
+			launcherForm.AppendItem(item)
+		} else {
+			owlcmsForm.AppendItem(item)
+		}
+	}
+
+	// other variables
+	var extraRows []*extraSettingRow
+	extraBox := container.NewVBox()
+	addExtraRow := func(key, value string) {
+		row := &extraSettingRow{
+			key:     widget.NewEntry(),
+			value:   widget.NewEntry(),
+			warning: widget.NewLabel(""),
+		}
+		row.key.SetPlaceHolder("VARIABLE_NAME")
+		row.key.SetText(key)
+		row.value.SetText(value)
+		row.warning.Importance = widget.WarningImportance
+		row.key.OnChanged = func(k string) {
+			row.warning.SetText(unknownKeyWarning(k))
+		}
+		row.warning.SetText(unknownKeyWarning(key))
+		removeButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+		row.row = container.NewVBox(
+			container.NewBorder(nil, nil, nil, removeButton,
+				container.NewGridWithColumns(2, row.key, row.value)),
+			row.warning)
+		removeButton.OnTapped = func() {
+			for i, r := range extraRows {
+				if r == row {
+					extraRows = append(extraRows[:i], extraRows[i+1:]...)
+					break
+				}
+			}
+			extraBox.Remove(row.row)
+		}
+		extraRows = append(extraRows, row)
+		extraBox.Add(row.row)
+	}
+	for _, key := range props.Keys() {
+		if findEnvSetting(key) == nil {
+			value, _ := props.Get(key)
+			addExtraRow(key, value)
+		}
+	}
+	addButton := widget.NewButtonWithIcon("Add Variable", theme.ContentAddIcon(), func() {
+		addExtraRow("", "")
+	})
+
+	saveButton := widget.NewButton("Save", nil)
+	saveButton.Importance = widget.HighImportance
+	saveButton.OnTapped = func() {
+		var errs []string
+		for _, setting := range envSchema {
+			if err := setting.validate(getters[setting.Key]()); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		extras := map[string]string{}
+		var extraKeys []string // in the order of the rows, so that saving twice writes the same file
+		for _, row := range extraRows {
+			key := strings.TrimSpace(row.key.Text)
+			if key == "" {
+				continue
+			}
+			if strings.ContainsAny(key, " =:") {
+				errs = append(errs, fmt.Sprintf("%q is not a valid variable name", key))
+				continue
+			}
+			if findEnvSetting(key) != nil {
+				errs = append(errs, fmt.Sprintf("%s is already listed above", key))
+				continue
+			}
+			if _, found := extras[key]; !found {
+				extraKeys = append(extraKeys, key)
+			}
+			extras[key] = row.value.Text
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("%s", strings.Join(errs, "\n")), win)
+			return
+		}
+
+		for _, setting := range envSchema {
+			trailing = setEnvValue(props, setting.Key, getters[setting.Key](), trailing)
+		}
+		for _, key := range props.Keys() {
+			if _, ok := extras[key]; !ok && findEnvSetting(key) == nil {
+				trailing = setEnvValue(props, key, "", trailing)
+			}
+		}
+		for _, key := range extraKeys {
+			trailing = setEnvValue(props, key, extras[key], trailing)
+		}
+
+		if err := writeEnvFile(props, header, trailing, envFilePath); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save %s: %w", envFilePath, err), win)
+			return
+		}
+		InitEnv()
+		log.Printf("Saved settings to %s\n", envFilePath)
+		win.Close()
+	}
+	cancelButton := widget.NewButton("Cancel", func() { win.Close() })
+	openButton := widget.NewButton("Open File Location", func() {
+		if err := openFileExplorer(owlcmsInstallDir); err != nil {
+			dialog.ShowError(err, win)
+		}
+	})
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("owlcms", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		owlcmsForm,
+		widget.NewLabelWithStyle("Launcher", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		launcherForm,
+		widget.NewLabelWithStyle("Other Variables", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		extraBox,
+		container.NewHBox(addButton),
+	)
+	buttons := container.NewHBox(openButton, layout.NewSpacer(), cancelButton, saveButton)
+	win.SetContent(container.NewBorder(nil, container.NewPadded(buttons), nil, nil,
+		container.NewVScroll(container.NewPadded(content))))
+	win.Resize(fyne.NewSize(700, 600))
+	win.Show()
+}
+
+// unknownKeyWarning flags variable names that look like a typo of a known one
+func unknownKeyWarning(key string) string {
+	key = strings.TrimSpace(key)
+	if key == "" || findEnvSetting(key) != nil {
+		return ""
+	}
+	if suggestion := suggestEnvKey(key); suggestion != "" {
+		return fmt.Sprintf("Unknown variable. Did you mean %s?", suggestion)
+	}
+	return ""
+}
+
+// setEnvValue sets key to value, or removes it if value is empty. The comments of a removed key
+// are moved to the trailing comments so that they are not lost.
+func setEnvValue(props *properties.Properties, key, value string, trailing []string) []string {
+	if value != "" {
+		props.Set(key, value)
+		return trailing
+	}
+	if _, ok := props.Get(key); ok {
+		for _, comment := range props.GetComments(key) {
+			trailing = append(trailing, "# "+comment)
+		}
+		props.Delete(key)
+	}
+	return trailing
+}
+
+// readEnvFile loads a properties file without expanding ${} references, together with the comment
+// lines that the properties package does not keep: the header of a file without any property, and
+// the comments found after the last property
+func readEnvFile(path string) (*properties.Properties, []string, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	props := properties.NewProperties()
+	props.DisableExpansion = true
+	if err := props.Load(content, properties.UTF8); err != nil {
+		return nil, nil, nil, err
+	}
+	comments := trailingComments(string(content))
+	if props.Len() == 0 {
+		return props, comments, nil, nil
+	}
+	return props, nil, comments, nil
+}
+
+// writeEnvFile writes the header comments, then the properties with their comments, followed by the
+// trailing comments
+func writeEnvFile(props *properties.Properties, header []string, trailing []string, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if len(header) > 0 {
+		if _, err := file.WriteString(strings.Join(header, "\n") + "\n\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := props.WriteComment(file, "# ", properties.UTF8); err != nil {
+		return err
+	}
+	if len(trailing) > 0 {
+		if _, err := file.WriteString("\n" + strings.Join(trailing, "\n") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trailingComments returns the comment lines that follow the last property in the file, or all of
+// them when the file has no property: the properties package only keeps the comments of a property
+func trailingComments(content string) []string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	last := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "!") {
+			last = i
+		}
+	}
+	trailing := lines[last+1:]
+	for len(trailing) > 0 && strings.TrimSpace(trailing[0]) == "" {
+		trailing = trailing[1:]
+	}
+	for len(trailing) > 0 && strings.TrimSpace(trailing[len(trailing)-1]) == "" {
+		trailing = trailing[:len(trailing)-1]
+	}
+	return trailing
+}
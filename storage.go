@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+	"owlcms-launcher/javacheck"
+)
+
+// dataUsage is the disk space taken by an installed version or a workspace
+type dataUsage struct {
+	Key         string // version name or workspace key
+	Files       int64  // everything but the database and the logs: the program, local files and settings
+	Database    int64
+	Logs        int64
+	Backups     int64
+	DataChanged time.Time // last change of the database, zero if there is none
+}
+
+var (
+	downloading      = map[string]bool{} // zips of releases being downloaded
+	downloadingMutex sync.Mutex
+)
+
+// markDownloading records that the zip of a release is being downloaded, so that it is not taken for a leftover
+func markDownloading(zipPath string, active bool) {
+	downloadingMutex.Lock()
+	defer downloadingMutex.Unlock()
+	if active {
+		downloading[zipPath] = true
+	} else {
+		delete(downloading, zipPath)
+	}
+}
+
+// isDownloading tells if the zip of a release is being downloaded
+func isDownloading(zipPath string) bool {
+	downloadingMutex.Lock()
+	defer downloadingMutex.Unlock()
+	return downloading[zipPath]
+}
+
+// leftover is a file or folder that no installation needs, such as the zip of an interrupted download
+type leftover struct {
+	Path        string
+	Description string
+	Size        int64
+}
+
+// measureUsage computes the space taken by a version, or by a workspace given its key
+func measureUsage(key string) dataUsage {
+	dir := getDataDir(key)
+	usage := dataUsage{
+		Key:         key,
+		Database:    downloadUtils.DirSize(filepath.Join(dir, "database")),
+		Logs:        downloadUtils.DirSize(filepath.Join(dir, "logs")),
+		Backups:     downloadUtils.DirSize(filepath.Join(getBackupDir(), key)),
+		DataChanged: lastModified(filepath.Join(dir, "database")),
+	}
+	usage.Files = downloadUtils.DirSize(dir) - usage.Database - usage.Logs
+	return usage
+}
+
+// lastModified returns the most recent modification time of the files under dir
+func lastModified(dir string) time.Time {
+	var latest time.Time
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// findLeftovers lists what remains of interrupted downloads and updates, older Java releases, and the
+// backups of versions and workspaces that were removed
+func findLeftovers() []leftover {
+	var leftovers []leftover
+	entries, _ := os.ReadDir(owlcmsInstallDir)
+	// an update or test upgrade in progress has its zip and staging folder in the installation directory
+	_, journalErr := os.Stat(getJournalPath())
+	updating := !os.IsNotExist(journalErr)
+	for _, entry := range entries {
+		path := filepath.Join(owlcmsInstallDir, entry.Name())
+		switch {
+		case !entry.IsDir() && strings.HasPrefix(entry.Name(), "owlcms_") && strings.HasSuffix(entry.Name(), ".zip") && !updating && !isDownloading(path):
+			if info, err := entry.Info(); err == nil {
+				leftovers = append(leftovers, leftover{Path: path, Description: "Interrupted download " + entry.Name(), Size: info.Size()})
+			}
+		case entry.IsDir() && strings.HasPrefix(entry.Name(), ".update-") && !updating:
+			leftovers = append(leftovers, leftover{Path: path, Description: "Unfinished update to " + strings.TrimPrefix(entry.Name(), ".update-"), Size: downloadUtils.DirSize(path)})
+		case entry.IsDir() && strings.HasPrefix(entry.Name(), ".rollback-"):
+			leftovers = append(leftovers, leftover{Path: path, Description: "Unfinished roll back of " + strings.TrimPrefix(entry.Name(), ".rollback-"), Size: downloadUtils.DirSize(path)})
+		}
+	}
+	if last := lastUpdate(); last != nil {
+		path := filepath.Join(getPreviousDir(), last.From)
+		if _, err := os.Stat(path); err == nil {
+			leftovers = append(leftovers, leftover{Path: getPreviousDir(), Description: fmt.Sprintf("Version %s, kept to roll back the update to %s", last.fromDescription(), last.To), Size: downloadUtils.DirSize(getPreviousDir())})
+		}
+	}
+	for _, rt := range javacheck.InstalledRuntimes() {
+		for _, dir := range javacheck.ObsoleteReleases(rt) {
+			leftovers = append(leftovers, leftover{Path: dir, Description: fmt.Sprintf("Older release of Java %d: %s", rt.Major, filepath.Base(dir)), Size: downloadUtils.DirSize(dir)})
+		}
+	}
+	keys := getAllDataKeys()
+	backupEntries, _ := os.ReadDir(getBackupDir())
+	for _, entry := range backupEntries {
+		if entry.IsDir() && !slices.Contains(keys, entry.Name()) {
+			path := filepath.Join(getBackupDir(), entry.Name())
+			leftovers = append(leftovers, leftover{Path: path, Description: fmt.Sprintf("Backups of %s, which is no longer installed (the only copy of its data)", entry.Name()), Size: downloadUtils.DirSize(path)})
+		}
+	}
+	return leftovers
+}
+
+// removeLeftover deletes a leftover file or folder
+func removeLeftover(l leftover) error {
+	if (currentProcess != nil || sandboxRunning) && strings.HasPrefix(l.Path, filepath.Join(owlcmsInstallDir, "java")) {
+		return fmt.Errorf("stop OWLCMS before removing Java releases")
+	}
+	log.Printf("Removing %s\n", l.Path)
+	if err := os.RemoveAll(l.Path); err != nil {
+		return fmt.Errorf("removing %s: %w", l.Path, err)
+	}
+	return nil
+}
+
+// retentionPolicy says which installed versions are kept when old ones are removed
+type retentionPolicy struct {
+	KeepStable      int // -1 when stable releases are not removed
+	KeepPrereleases int // -1 when prereleases are not removed
+	RecentDays      int // versions whose database changed within this many days are kept; 0 for no such rule
+}
+
+// getRetentionSetting reads a number of the retention policy, -1 if it is not set or below minimum
+func getRetentionSetting(key string, minimum int) int {
+	value := GetSetting(key, "")
+	if value == "" {
+		return -1
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < minimum {
+		log.Printf("Invalid %s %q, ignored\n", key, value)
+		return -1
+	}
+	return count
+}
+
+// getRetentionPolicy returns the retention policy set in env.properties
+func getRetentionPolicy() retentionPolicy {
+	return retentionPolicy{
+		// keeping no version at all would remove the one just installed
+		KeepStable:      getRetentionSetting("OWLCMS_LAUNCHER_KEEPSTABLE", 1),
+		KeepPrereleases: getRetentionSetting("OWLCMS_LAUNCHER_KEEPPRERELEASES", 1),
+		RecentDays:      max(getRetentionSetting("OWLCMS_LAUNCHER_KEEPRECENTDAYS", 0), 0),
+	}
+}
+
+// enabled tells if the policy removes anything at all
+func (p retentionPolicy) enabled() bool {
+	return p.KeepStable >= 0 || p.KeepPrereleases >= 0
+}
+
+// String describes the policy for the Storage window
+func (p retentionPolicy) String() string {
+	if !p.enabled() {
+		return "No retention policy: set OWLCMS_LAUNCHER_KEEPSTABLE or OWLCMS_LAUNCHER_KEEPPRERELEASES in the Settings."
+	}
+	var parts []string
+	if p.KeepStable >= 0 {
+		parts = append(parts, fmt.Sprintf("the %d most recent stable version(s)", p.KeepStable))
+	} else {
+		parts = append(parts, "all stable versions")
+	}
+	if p.KeepPrereleases >= 0 {
+		parts = append(parts, fmt.Sprintf("the %d most recent prerelease(s)", p.KeepPrereleases))
+	} else {
+		parts = append(parts, "all prereleases")
+	}
+	description := "Keep " + strings.Join(parts, " and ")
+	if p.RecentDays > 0 {
+		description += fmt.Sprintf(", and any version whose database changed in the last %d days", p.RecentDays)
+	}
+	return description + ".\nInstallations given a name of their own, run by a workspace or running are always kept."
+}
+
+// retentionCandidates returns the installed versions that the policy removes, oldest last. The versions
+// in keep, such as the one just installed, are never removed.
+func retentionCandidates(p retentionPolicy, keep ...string) []string {
+	if !p.enabled() {
+		return nil
+	}
+	inUse := slices.Clone(keep)
+	if currentProcess != nil {
+		inUse = append(inUse, currentVersion)
+	}
+	for _, ws := range listWorkspaces() {
+		inUse = append(inUse, ws.Version)
+	}
+	recent := time.Now().AddDate(0, 0, -p.RecentDays)
+
+	var candidates []string
+	stable, prereleases := 0, 0
+	// the list is sorted most recent first, so the count tells how many newer versions are kept
+	for _, name := range getAllInstalledVersions() {
+		if !releaseNamePattern.MatchString(name) {
+			continue
+		}
+		kept := p.KeepStable
+		count := &stable
+		if containsPreReleaseTag(name) {
+			kept = p.KeepPrereleases
+			count = &prereleases
+		}
+		*count++
+		if kept < 0 || *count <= kept || slices.Contains(inUse, name) {
+			continue
+		}
+		if p.RecentDays > 0 && lastModified(filepath.Join(owlcmsInstallDir, name, "database")).After(recent) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
+
+// removeVersions removes installed versions after backing up their data, and returns those removed
+func removeVersions(names []string) ([]string, error) {
+	var removed []string
+	for _, name := range names {
+		if currentProcess != nil && currentVersion == name {
+			continue
+		}
+		if _, err := backupVersion(name, "remove"); err != nil {
+			return removed, fmt.Errorf("%s was not removed, its data could not be backed up: %w", name, err)
+		}
+		if err := os.RemoveAll(filepath.Join(owlcmsInstallDir, name)); err != nil {
+			return removed, fmt.Errorf("failed to remove OWLCMS %s: %w", name, err)
+		}
+		log.Printf("Removed %s\n", name)
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"owlcms-launcher/downloadUtils"
+	"owlcms-launcher/javacheck"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showStorageWindow summarizes the disk space taken by the versions, workspaces, backups, Java runtimes
+// and leftovers, and applies the retention policy
+func showStorageWindow(w fyne.Window) {
+	win := fyne.CurrentApp().NewWindow("Storage")
+	content := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		content.Objects = []fyne.CanvasObject{widget.NewLabel("Measuring...")}
+		content.Refresh()
+		go func() {
+			objects := storageSummary(win, refresh)
+			content.Objects = objects
+			content.Refresh()
+		}()
+	}
+
+	policy := getRetentionPolicy()
+	applyButton := widget.NewButton("Apply Retention Policy", func() {
+		confirmRetention(win, false, "", func() {
+			recomputeVersionList(w)
+			refresh()
+		})
+	})
+	if !policy.enabled() {
+		applyButton.Disable()
+	}
+	settingsButton := widget.NewButton("Settings", func() {
+		showSettingsWindow(getEnvFilePath(), "Settings")
+	})
+	bottom := container.NewBorder(nil, nil, nil, container.NewHBox(settingsButton, applyButton), widget.NewLabel(policy.String()))
+
+	refresh()
+	win.SetContent(container.NewBorder(nil, bottom, nil, nil, container.NewVScroll(content)))
+	win.Resize(fyne.NewSize(900, 600))
+	win.Show()
+}
+
+// storageSummary builds the sections of the Storage window
+func storageSummary(win fyne.Window, refresh func()) []fyne.CanvasObject {
+	var total int64
+	candidates := retentionCandidates(getRetentionPolicy())
+
+	usageHeader := func(first string) fyne.CanvasObject {
+		bold := fyne.TextStyle{Bold: true}
+		return container.NewGridWithColumns(7,
+			widget.NewLabelWithStyle(first, fyne.TextAlignLeading, bold),
+			widget.NewLabelWithStyle("Files", fyne.TextAlignTrailing, bold),
+			widget.NewLabelWithStyle("Database", fyne.TextAlignTrailing, bold),
+			widget.NewLabelWithStyle("Logs", fyne.TextAlignTrailing, bold),
+			widget.NewLabelWithStyle("Backups", fyne.TextAlignTrailing, bold),
+			widget.NewLabelWithStyle("Data changed", fyne.TextAlignTrailing, bold),
+			widget.NewLabel(""))
+	}
+	usageRow := func(title string, u dataUsage, note string) fyne.CanvasObject {
+		total += u.Files + u.Database + u.Logs + u.Backups
+		changed := "no database"
+		if !u.DataChanged.IsZero() {
+			changed = u.DataChanged.Format("2006-01-02")
+		}
+		name := widget.NewLabel(title)
+		name.Truncation = fyne.TextTruncateEllipsis
+		return container.NewGridWithColumns(7,
+			name,
+			widget.NewLabelWithStyle(downloadUtils.FormatSize(u.Files), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(downloadUtils.FormatSize(u.Database), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(downloadUtils.FormatSize(u.Logs), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(downloadUtils.FormatSize(u.Backups), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(changed, fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabel(note))
+	}
+
+	objects := []fyne.CanvasObject{usageHeader("Version")}
+	for _, name := range getAllInstalledVersions() {
+		note := ""
+		if slices.Contains(candidates, name) {
+			note = "removed by policy"
+		}
+		objects = append(objects, usageRow(name, measureUsage(name), note))
+	}
+	if workspaces := listWorkspaces(); len(workspaces) > 0 {
+		objects = append(objects, widget.NewSeparator(), usageHeader("Workspace"))
+		for _, ws := range workspaces {
+			objects = append(objects, usageRow(ws.Name, measureUsage(workspaceKey(ws.Name)), "runs "+ws.Version))
+		}
+	}
+
+	if runtimes := javacheck.InstalledRuntimes(); len(runtimes) > 0 {
+		objects = append(objects, widget.NewSeparator(), widget.NewLabelWithStyle("Java runtimes", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		users := runtimeUsers(runtimes)
+		for _, rt := range runtimes {
+			size := downloadUtils.DirSize(rt.Dir)
+			total += size
+			description := fmt.Sprintf("Java %d (%s), %s", rt.Major, rt.Release, downloadUtils.FormatSize(size))
+			if versions := users[rt.Major]; len(versions) > 0 {
+				description += ", used by " + strings.Join(versions, ", ")
+			} else {
+				description += ", not used by any installed version"
+			}
+			objects = append(objects, widget.NewLabel(description))
+		}
+	}
+
+	leftovers := findLeftovers()
+	if len(leftovers) > 0 {
+		objects = append(objects, widget.NewSeparator(), widget.NewLabelWithStyle("Leftovers", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, l := range leftovers {
+			l := l
+			total += l.Size
+			removeButton := widget.NewButton("Delete", func() {
+				dialog.ShowConfirm("Delete", fmt.Sprintf("Delete %s?\n%s", l.Description, l.Path), func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := removeLeftover(l); err != nil {
+						dialog.ShowError(err, win)
+					}
+					refresh()
+				}, win)
+			})
+			objects = append(objects, container.NewBorder(nil, nil, nil, removeButton,
+				widget.NewLabel(fmt.Sprintf("%s, %s", l.Description, downloadUtils.FormatSize(l.Size)))))
+		}
+	}
+
+	summary := widget.NewLabelWithStyle(fmt.Sprintf("%s used in %s and %s", downloadUtils.FormatSize(total), owlcmsInstallDir, getBackupDir()),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	return append([]fyne.CanvasObject{summary, widget.NewSeparator()}, objects...)
+}
+
+// confirmRetention lists the versions removed by the retention policy and removes them once confirmed.
+// When quiet, nothing is shown if the policy removes nothing. The installed version, if any, is kept.
+func confirmRetention(w fyne.Window, quiet bool, installed string, done func()) {
+	candidates := retentionCandidates(getRetentionPolicy(), installed)
+	if len(candidates) == 0 {
+		if !quiet {
+			dialog.ShowInformation("Retention Policy", "No version needs to be removed.", w)
+		}
+		return
+	}
+	message := fmt.Sprintf("The retention policy removes %s.\nTheir database and local files are backed up first.", strings.Join(candidates, ", "))
+	dialog.ShowConfirm("Remove Old Versions", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		removed, err := removeVersions(candidates)
+		if err != nil {
+			dialog.ShowError(err, w)
+		} else if len(removed) < len(candidates) {
+			dialog.ShowInformation("Retention Policy", fmt.Sprintf("Removed %s. The running version was kept.", strings.Join(removed, ", ")), w)
+		}
+		done()
+	}, w)
+}
+
+// retentionAfterInstall offers to apply the retention policy once version has been installed or updated to,
+// when OWLCMS_LAUNCHER_CLEANUPAFTERUPDATE is set
+func retentionAfterInstall(version string, w fyne.Window) {
+	if !GetBoolSetting("OWLCMS_LAUNCHER_CLEANUPAFTERUPDATE") {
+		return
+	}
+	confirmRetention(w, true, version, func() {
+		recomputeVersionList(w)
+		checkForNewerVersion()
+	})
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+	"owlcms-launcher/javacheck"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sandboxLogLines is the number of lines of the sandbox output shown when the test fails
+const sandboxLogLines = 40
+
+// sandboxRunning is true while a test upgrade runs a server, which uses a Java runtime like the launched one
+var sandboxRunning bool
+
+// sandboxOverrides replace variables of the installed version that would keep the test from using the copied
+// database: in memory or reset mode, the migration would not be tested at all
+var sandboxOverrides = map[string]string{
+	"OWLCMS_MEMORYMODE": "false",
+	"OWLCMS_RESETMODE":  "false",
+}
+
+// checkUpdateTarget refuses an update whose result would replace another installation: an installation
+// named after its release moves to the folder of the new release, which must be free
+func checkUpdateTarget(j *updateJournal) error {
+	if j.target() == j.From {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(owlcmsInstallDir, j.target())); err == nil {
+		return fmt.Errorf("version %s is already installed", j.target())
+	}
+	return nil
+}
+
+// checkNoUpdateInProgress refuses to start an update while another one, or a test, is under way
+func checkNoUpdateInProgress() error {
+	if j, _ := readJournal(getJournalPath()); j != nil {
+		return fmt.Errorf("an update from %s to %s is already in progress", j.From, j.To)
+	}
+	return nil
+}
+
+// findFreePort returns a local port that no program is listening on
+func findFreePort() (string, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// testUpgrade installs the target version in a sandbox with a copy of the data of the installed version,
+// starts it on a temporary port to check that it migrates the database, then offers to promote or discard it
+func testUpgrade(existingVersion string, targetVersion string, w fyne.Window) {
+	if currentProcess != nil && currentVersion == existingVersion {
+		dialog.ShowError(fmt.Errorf("stop OWLCMS %s before testing the upgrade, its database is in use", existingVersion), w)
+		return
+	}
+	journal := newUpdateJournal(existingVersion, targetVersion, updateSandbox)
+	if err := checkUpdateTarget(journal); err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	if err := checkNoUpdateInProgress(); err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	progressLabel := widget.NewLabel(fmt.Sprintf("Downloading OWLCMS %s into a sandbox...", targetVersion))
+	progressDialog := dialog.NewCustomWithoutButtons("Testing Upgrade", progressLabel, w)
+	progressDialog.Show()
+
+	go func() {
+		if err := writeJournal(getJournalPath(), journal); err != nil {
+			progressDialog.Hide()
+			dialog.ShowError(err, w)
+			return
+		}
+		if _, err := stageUpdate(journal); err != nil {
+			abortUpdate(journal)
+			progressDialog.Hide()
+			dialog.ShowError(fmt.Errorf("test upgrade failed: %w", err), w)
+			return
+		}
+
+		progressLabel.SetText(fmt.Sprintf("Starting OWLCMS %s with a copy of the %s database...", targetVersion, existingVersion))
+		start := time.Now()
+		logPath, err := runSandbox(journal, progressLabel)
+		progressDialog.Hide()
+		if err != nil {
+			log.Printf("Test upgrade to %s failed: %v\n", targetVersion, err)
+			showSandboxFailure(journal, err, logPath, w)
+			return
+		}
+
+		message := fmt.Sprintf("OWLCMS %s started with a copy of the %s data in %d seconds.\n"+
+			"Promote replaces %s by %s, with the data as it is now; Discard removes the sandbox.",
+			targetVersion, existingVersion, int(time.Since(start).Seconds()), existingVersion, targetVersion)
+		dialog.ShowCustomConfirm("Test Upgrade Succeeded", "Promote", "Discard", widget.NewLabel(message), func(promote bool) {
+			if !promote {
+				abortUpdate(journal)
+				return
+			}
+			if conflicts, err := promoteSandbox(journal); err != nil {
+				dialog.ShowError(fmt.Errorf("promotion failed: %w", err), w)
+			} else {
+				dialog.ShowInformation("Update Complete", fmt.Sprintf("Successfully updated to version %s", targetVersion), w)
+				if len(conflicts) > 0 {
+					showMergeConflicts(journal.target(), conflicts)
+				}
+			}
+			recomputeVersionList(w)
+			checkForNewerVersion()
+		}, w)
+	}()
+}
+
+// runSandbox starts the sandboxed version on a free port, waits until it answers and stops it.
+// The output of the server is kept in a log file in the sandbox, whose path is returned. Progress, such as
+// the download of a Java runtime, is shown in progressLabel so that the main screen is left as it is.
+func runSandbox(j *updateJournal, progressLabel *widget.Label) (string, error) {
+	sandboxDir := getStagingDir(j.target())
+	logPath := filepath.Join(sandboxDir, "test-upgrade.log")
+
+	required, err := javacheck.RequiredJavaVersion(filepath.Join(sandboxDir, "owlcms.jar"))
+	if err != nil {
+		required = javacheck.DefaultJavaVersion
+	}
+	required = max(required, javacheck.DefaultJavaVersion)
+	if err := javacheck.CheckJava(required, progressLabel); err != nil {
+		return "", fmt.Errorf("could not install a Java %d runtime: %w", required, err)
+	}
+	javaPath, _, err := javacheck.FindJava(required)
+	if err != nil {
+		return "", err
+	}
+	port, err := findFreePort()
+	if err != nil {
+		return "", fmt.Errorf("finding a free port: %w", err)
+	}
+
+	// the sandbox gets the variables of the installed version, except for the port and the database modes
+	_, values, _ := effectiveEnvironment(j.From, "", "")
+	env := append(os.Environ(), fmt.Sprintf("OWLCMS_LAUNCHER=%s", j.To))
+	for key, value := range values {
+		if _, overridden := sandboxOverrides[key]; key != "OWLCMS_PORT" && !overridden {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	for key, value := range sandboxOverrides {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	env = append(env, "OWLCMS_PORT="+port)
+	javaArgs, _, err := buildJavaArguments("owlcms.jar", values["JAVA_OPTIONS"], values["PROGRAM_ARGUMENTS"])
+	if err != nil {
+		return "", err
+	}
+
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+	cmd := exec.Command(javaPath, javaArgs...)
+	cmd.Dir = sandboxDir
+	cmd.Env = env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	log.Printf("Testing OWLCMS %s on port %s with command: %s\n", j.To, port, formatCommandLine(cmd.Args))
+	if err := cmd.Start(); err != nil {
+		return logPath, fmt.Errorf("failed to start OWLCMS %s: %w", j.To, err)
+	}
+	sandboxRunning = true
+	defer func() { sandboxRunning = false }()
+
+	ready, exited := monitorProcess(cmd, port)
+	readyErr := <-ready
+	if readyErr == nil {
+		log.Printf("OWLCMS %s is ready in the sandbox, stopping it\n", j.To)
+	}
+
+	// stop the sandbox server, forcibly if it does not exit by itself
+	if downloadUtils.GetGoos() == "windows" {
+		cmd.Process.Kill()
+	} else {
+		cmd.Process.Signal(syscall.SIGINT)
+	}
+	select {
+	case <-exited:
+	case <-time.After(30 * time.Second):
+		cmd.Process.Kill()
+		<-exited
+	}
+	return logPath, readyErr
+}
+
+// showSandboxFailure shows the end of the sandbox output and discards the sandbox once the user has read it
+func showSandboxFailure(j *updateJournal, err error, logPath string, w fyne.Window) {
+	logText := widget.NewLabel(tailFile(logPath, sandboxLogLines))
+	logText.TextStyle = fyne.TextStyle{Monospace: true}
+	scroll := container.NewScroll(logText)
+	scroll.SetMinSize(fyne.NewSize(700, 350))
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("OWLCMS %s did not start with a copy of the %s data: %v\nVersion %s is unchanged.", j.To, j.From, err, j.From)),
+		nil, nil, nil, scroll)
+	failureDialog := dialog.NewCustom("Test Upgrade Failed", "Discard Sandbox", content, w)
+	failureDialog.SetOnClosed(func() {
+		abortUpdate(j)
+	})
+	failureDialog.Show()
+}
+
+// tailFile returns the last lines of a text file
+func tailFile(path string, lines int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("(no output: %v)", err)
+	}
+	defer f.Close()
+	var tail []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		tail = append(tail, scanner.Text())
+		if len(tail) > lines {
+			tail = tail[1:]
+		}
+	}
+	return strings.Join(tail, "\n")
+}
+
+// promoteSandbox turns a tested sandbox into the installed version. The data is copied again, since the
+// sandbox holds the copy migrated by the test and the installed version may have been used since.
+// It returns the customized files whose merge with the new release has conflicts.
+func promoteSandbox(j *updateJournal) ([]*fileMerge, error) {
+	if currentProcess != nil && currentVersion == j.From {
+		return nil, fmt.Errorf("stop OWLCMS %s before promoting %s", j.From, j.To)
+	}
+	if _, err := backupVersion(j.From, "update"); err != nil {
+		return nil, fmt.Errorf("the current data could not be backed up: %w", err)
+	}
+	// local is kept: it holds the files of the release, over which the modified ones are copied again
+	sandboxDir := getStagingDir(j.target())
+	for _, name := range []string{"database", "env.properties", "test-upgrade.log"} {
+		if err := os.RemoveAll(filepath.Join(sandboxDir, name)); err != nil {
+			return nil, err
+		}
+	}
+	conflicts, err := copyVersionData(filepath.Join(owlcmsInstallDir, j.From), sandboxDir)
+	if err != nil {
+		return nil, err
+	}
+	j.State = updateStaged
+	if err := writeJournal(getJournalPath(), j); err != nil {
+		return nil, err
+	}
+	return conflicts, commitUpdate(j)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDiffCells bounds the size of the table used to compare two texts, about 100 MB.
+// Larger files are not merged.
+const maxDiffCells = 25_000_000
+
+// isText tells if a file can be merged line by line
+func isText(content []byte) bool {
+	return !bytes.ContainsRune(content, 0) && utf8.Valid(content)
+}
+
+// splitLines splits a text after each newline, so that joining the lines gives the text back unchanged
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines returns, for each line of a, the index of the same line in b in a longest common subsequence
+// of a and b, or -1. It returns false if the texts are too large to compare.
+func matchLines(a, b []string) ([]int, bool) {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+	// lines common to the start and the end do not need the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+	n, m := len(a)-prefix-suffix, len(b)-prefix-suffix
+	if n == 0 || m == 0 {
+		return matches, true
+	}
+	if n*m > maxDiffCells {
+		return nil, false
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of the middle of a from i and of b from j
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[prefix+i] == b[prefix+j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[prefix+i] == b[prefix+j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches, true
+}
+
+// mergeHunk is a part of a three-way merge. Outside conflicts, Result holds the merged lines.
+type mergeHunk struct {
+	Conflict bool
+	Result   []string
+	Base     []string
+	Mine     []string
+	Theirs   []string
+}
+
+// mergeLines merges the changes made from base to mine and from base to theirs, in the manner of diff3.
+// Regions changed on one side only take that side; regions changed differently on both sides are conflicts.
+// It returns false if the texts are too large to compare.
+func mergeLines(base, mine, theirs []string) ([]mergeHunk, bool) {
+	toMine, ok := matchLines(base, mine)
+	if !ok {
+		return nil, false
+	}
+	toTheirs, ok := matchLines(base, theirs)
+	if !ok {
+		return nil, false
+	}
+
+	var hunks []mergeHunk
+	o, a, b := 0, 0, 0
+	for {
+		// lines unchanged on both sides
+		start := o
+		for o < len(base) && toMine[o] == a && toTheirs[o] == b {
+			o++
+			a++
+			b++
+		}
+		if o > start {
+			hunks = append(hunks, mergeHunk{Result: base[start:o]})
+		}
+		if o == len(base) && a == len(mine) && b == len(theirs) {
+			return hunks, true
+		}
+
+		// the next line unchanged on both sides ends the changed region
+		next, nextMine, nextTheirs := len(base), len(mine), len(theirs)
+		for k := o; k < len(base); k++ {
+			if toMine[k] >= 0 && toTheirs[k] >= 0 {
+				next, nextMine, nextTheirs = k, toMine[k], toTheirs[k]
+				break
+			}
+		}
+		hunk := mergeHunk{Base: base[o:next], Mine: mine[a:nextMine], Theirs: theirs[b:nextTheirs]}
+		switch {
+		case equalLines(hunk.Mine, hunk.Base):
+			hunk.Result = hunk.Theirs
+		case equalLines(hunk.Theirs, hunk.Base), equalLines(hunk.Mine, hunk.Theirs):
+			hunk.Result = hunk.Mine
+		default:
+			hunk.Conflict = true
+		}
+		hunks = append(hunks, hunk)
+		o, a, b = next, nextMine, nextTheirs
+	}
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasConflicts tells if a merge needs a decision from the user
+func hasConflicts(hunks []mergeHunk) bool {
+	for _, h := range hunks {
+		if h.Conflict {
+			return true
+		}
+	}
+	return false
+}
+
+// joinMerge assembles the merged text. resolve gives the lines kept for each conflict, by conflict number.
+func joinMerge(hunks []mergeHunk, resolve func(conflict int, h mergeHunk) []string) string {
+	var b strings.Builder
+	conflict := 0
+	for _, h := range hunks {
+		lines := h.Result
+		if h.Conflict {
+			lines = resolve(conflict, h)
+			conflict++
+		}
+		for _, line := range lines {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
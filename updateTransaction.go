@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+)
+
+// An update goes through two states, recorded in the journal so that an interrupted update can be finished
+// or undone at the next start. While staging, the installed version is not touched; once staged, the new
+// version is complete and committing it only renames directories. A sandbox is a staged version being
+// tried out; it is discarded if the launcher stops before it is promoted.
+const (
+	updateStaging = "staging"
+	updateStaged  = "staged"
+	updateSandbox = "sandbox"
+)
+
+// updateJournal records an update in progress, and the last completed one for rolling it back.
+// From and Target are directory names: an installation named after its release moves to the folder of
+// the new release, one with a name of its own is updated in place.
+type updateJournal struct {
+	From        string    `json:"from"`
+	FromRelease string    `json:"fromRelease,omitempty"`
+	To          string    `json:"to"`
+	Target      string    `json:"target,omitempty"`
+	State       string    `json:"state"`
+	Started     time.Time `json:"started"`
+}
+
+// newUpdateJournal starts the journal of the update of installation name to release version
+func newUpdateJournal(name, version, state string) *updateJournal {
+	j := &updateJournal{From: name, FromRelease: instanceVersion(name), To: version, Target: name, State: state, Started: time.Now()}
+	if name == j.FromRelease {
+		j.Target = version
+	}
+	return j
+}
+
+// target returns the directory of the installation once updated. Journals written before named
+// installations existed have no target and moved to the release folder.
+func (j *updateJournal) target() string {
+	if j.Target == "" {
+		return j.To
+	}
+	return j.Target
+}
+
+// fromDescription names the installation before the update, with its release when the name differs
+func (j *updateJournal) fromDescription() string {
+	if j.FromRelease == "" || j.FromRelease == j.From {
+		return j.From
+	}
+	return fmt.Sprintf("%s (%s)", j.From, j.FromRelease)
+}
+
+// getJournalPath returns the file recording the update in progress
+func getJournalPath() string {
+	return filepath.Join(owlcmsInstallDir, "update.journal")
+}
+
+// getPreviousDir returns the folder keeping the version replaced by the last update.
+// Its name does not look like a version, so it is not shown in the version list.
+func getPreviousDir() string {
+	return filepath.Join(owlcmsInstallDir, "previous")
+}
+
+// getStagingDir returns the folder in which the installation name is prepared before replacing the installed one
+func getStagingDir(name string) string {
+	return filepath.Join(owlcmsInstallDir, ".update-"+name)
+}
+
+// writeJournal saves a journal atomically, so that a crash leaves either the old or the new state
+func writeJournal(path string, j *updateJournal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// readJournal returns the journal saved at path, or nil if there is none
+func readJournal(path string) (*updateJournal, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var j updateJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// getReleaseURL returns the download address of the zip of a version
+func getReleaseURL(version string) string {
+	urlPrefix := "https://github.com/owlcms/owlcms4/releases/download"
+	if containsPreReleaseTag(version) {
+		urlPrefix = "https://github.com/owlcms/owlcms4-prerelease/releases/download"
+	}
+	return fmt.Sprintf("%s/%s/owlcms_%s.zip", urlPrefix, version, version)
+}
+
+// stageUpdate downloads the target version into the staging folder and copies the data of the installed
+// version into it. The installed version is only read. It returns the customized files whose merge with
+// the new release has conflicts.
+func stageUpdate(j *updateJournal) ([]*fileMerge, error) {
+	stagingDir := getStagingDir(j.target())
+	os.RemoveAll(stagingDir)
+
+	zipPath := filepath.Join(owlcmsInstallDir, fmt.Sprintf("owlcms_%s.zip", j.To))
+	if err := downloadUtils.DownloadArchive(getReleaseURL(j.To), zipPath); err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	checksum := hashDownload(zipPath)
+	if err := downloadUtils.ExtractZipWithManifest(zipPath, stagingDir); err != nil {
+		os.Remove(zipPath)
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(stagingDir, "owlcms.jar")); err != nil {
+		return nil, fmt.Errorf("owlcms.jar missing from the downloaded version")
+	}
+	recordInstallation(filepath.Base(stagingDir), j.To, getReleaseURL(j.To), checksum, j.From)
+	return copyVersionData(filepath.Join(owlcmsInstallDir, j.From), stagingDir)
+}
+
+// copyVersionData copies the database, the version variables and the locally modified files of a version.
+// It returns the modified files that could not be merged with the release in destDir.
+func copyVersionData(currentVersionDir, destDir string) ([]*fileMerge, error) {
+	// Copy the database from the current version to the new version
+	if _, err := os.Stat(filepath.Join(currentVersionDir, "database")); err == nil {
+		if err := copyFiles(filepath.Join(currentVersionDir, "database"), filepath.Join(destDir, "database"), true); err != nil {
+			return nil, fmt.Errorf("failed to copy database: %w", err)
+		}
+	} else {
+		log.Printf("No database files to copy from %s\n", currentVersionDir)
+	}
+
+	// Keep the variables specific to the version
+	if _, err := os.Stat(filepath.Join(currentVersionDir, "env.properties")); err == nil {
+		if err := copyFiles(filepath.Join(currentVersionDir, "env.properties"), filepath.Join(destDir, "env.properties"), true); err != nil {
+			return nil, fmt.Errorf("failed to copy version env.properties: %w", err)
+		}
+	}
+
+	// Copy the locally modified files to the new version
+	if _, err := os.Stat(filepath.Join(currentVersionDir, "local")); err == nil {
+		conflicts, err := copyLocalFiles(currentVersionDir, destDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy local files: %w", err)
+		}
+		return conflicts, nil
+	}
+	return nil, nil
+}
+
+// commitUpdate puts the staged version in place and keeps the replaced one for rolling back.
+// Each step checks what is already done, so it can be repeated after an interruption.
+func commitUpdate(j *updateJournal) error {
+	stagingDir := getStagingDir(j.target())
+	targetDir := filepath.Join(owlcmsInstallDir, j.target())
+	currentVersionDir := filepath.Join(owlcmsInstallDir, j.From)
+	previousVersionDir := filepath.Join(getPreviousDir(), j.From)
+
+	// the installed version is moved aside first, since an installation with a name of its own is replaced
+	// by a folder of the same name. Once the staging folder is gone, that folder is the new version.
+	_, stagingErr := os.Stat(stagingDir)
+	staged := stagingErr == nil
+	if _, err := os.Stat(currentVersionDir); err == nil && (staged || j.From != j.target()) {
+		// only the last replaced version is kept
+		if err := os.RemoveAll(getPreviousDir()); err != nil {
+			return fmt.Errorf("removing the version replaced by the previous update: %w", err)
+		}
+		if err := os.MkdirAll(getPreviousDir(), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(currentVersionDir, previousVersionDir); err != nil {
+			return fmt.Errorf("keeping %s for rollback: %w", j.From, err)
+		}
+	}
+	if staged {
+		if err := os.Rename(stagingDir, targetDir); err != nil {
+			return fmt.Errorf("installing %s: %w", j.To, err)
+		}
+	}
+	if err := writeJournal(filepath.Join(getPreviousDir(), "update.json"), j); err != nil {
+		log.Printf("Failed to record the update for rollback: %v\n", err)
+	}
+	log.Printf("Updated %s to %s\n", j.From, j.To)
+	return os.Remove(getJournalPath())
+}
+
+// abortUpdate removes what an unfinished update has created. The installed version was not modified.
+func abortUpdate(j *updateJournal) {
+	os.RemoveAll(getStagingDir(j.target()))
+	os.Remove(filepath.Join(owlcmsInstallDir, fmt.Sprintf("owlcms_%s.zip", j.To)))
+	if err := os.Remove(getJournalPath()); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove update journal: %v\n", err)
+	}
+}
+
+// recoverUpdate finishes or undoes an update interrupted by a crash or by closing the launcher,
+// and returns a message for the user, empty if there was nothing to do
+func recoverUpdate() string {
+	j, err := readJournal(getJournalPath())
+	if err != nil {
+		log.Printf("Ignoring damaged update journal: %v\n", err)
+		os.Remove(getJournalPath())
+		return ""
+	}
+	if j == nil {
+		return ""
+	}
+	if j.State == updateStaged {
+		if err := commitUpdate(j); err != nil {
+			log.Printf("Failed to complete the update to %s: %v\n", j.To, err)
+			return fmt.Sprintf("The interrupted update from %s to %s could not be completed: %v", j.From, j.To, err)
+		}
+		return fmt.Sprintf("The interrupted update from %s to %s has been completed.", j.From, j.To)
+	}
+	abortUpdate(j)
+	log.Printf("Rolled back the interrupted update from %s to %s\n", j.From, j.To)
+	return fmt.Sprintf("The update from %s to %s was interrupted and has been cancelled. Version %s is unchanged.", j.From, j.To, j.From)
+}
+
+// lastUpdate returns the update that can be rolled back, or nil
+func lastUpdate() *updateJournal {
+	j, err := readJournal(filepath.Join(getPreviousDir(), "update.json"))
+	if err != nil || j == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(getPreviousDir(), j.From)); err != nil {
+		return nil
+	}
+	return j
+}
+
+// rollbackUpdate reinstates the version replaced by an update, with its data as it was before the update.
+// The data of the newer version is backed up first, then that version is removed.
+func rollbackUpdate(j *updateJournal) error {
+	targetDir := filepath.Join(owlcmsInstallDir, j.target())
+	restoredDir := filepath.Join(owlcmsInstallDir, j.From)
+	if _, err := os.Stat(restoredDir); err == nil && j.From != j.target() {
+		return fmt.Errorf("version %s is already installed", j.From)
+	}
+	if _, err := backupVersion(j.target(), "rollback"); err != nil {
+		return fmt.Errorf("the data of %s could not be backed up: %w", j.target(), err)
+	}
+	// the newer version is moved aside, since it may have the name of the one coming back
+	discardedDir := filepath.Join(owlcmsInstallDir, ".rollback-"+j.target())
+	os.RemoveAll(discardedDir)
+	if err := os.Rename(targetDir, discardedDir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", j.target(), err)
+	}
+	if err := os.Rename(filepath.Join(getPreviousDir(), j.From), restoredDir); err != nil {
+		os.Rename(discardedDir, targetDir)
+		return fmt.Errorf("reinstating %s: %w", j.From, err)
+	}
+	if err := os.RemoveAll(getPreviousDir()); err != nil {
+		log.Printf("Failed to clean up %s: %v\n", getPreviousDir(), err)
+	}
+	if err := os.RemoveAll(discardedDir); err != nil {
+		return fmt.Errorf("removing %s: %w", j.target(), err)
+	}
+	log.Printf("Rolled back %s from %s to %s\n", j.target(), j.To, j.fromDescription())
+	return nil
+}
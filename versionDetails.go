@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"owlcms-launcher/downloadUtils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// versionDetails shows what install.properties records about the installation selected in the version list
+var versionDetails *fyne.Container
+
+// getReleaseNotesURL returns the page describing a release
+func getReleaseNotesURL(version string) string {
+	repository := "owlcms4"
+	if containsPreReleaseTag(version) {
+		repository = "owlcms4-prerelease"
+	}
+	return fmt.Sprintf("https://github.com/owlcms/%s/releases/tag/%s", repository, version)
+}
+
+// formatRecordedTime shows a time saved in install.properties in local time
+func formatRecordedTime(recorded string) string {
+	if recorded == "" {
+		return "not recorded"
+	}
+	t, err := time.Parse(time.RFC3339, recorded)
+	if err != nil {
+		return recorded
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// showVersionDetails fills the details pane with the origin and use of an installation, and lets its
+// label and notes be edited
+func showVersionDetails(name string, w fyne.Window) {
+	if versionDetails == nil {
+		return
+	}
+	info := readInstanceInfo(name)
+
+	valueLabel := func(text string) *widget.Label {
+		if text == "" {
+			text = "not recorded"
+		}
+		label := widget.NewLabel(text)
+		label.Truncation = fyne.TextTruncateEllipsis
+		return label
+	}
+	sizeLabel := widget.NewLabel("computing...")
+	go func() {
+		size := downloadUtils.FormatSize(downloadUtils.DirSize(filepath.Join(owlcmsInstallDir, name)))
+		if info.Size > 0 {
+			size += fmt.Sprintf(" (%s when installed)", downloadUtils.FormatSize(info.Size))
+		}
+		sizeLabel.SetText(size)
+	}()
+	checksum := info.Checksum
+	if len(checksum) > 16 {
+		checksum = checksum[:16] + "…"
+	}
+	updatedFrom := info.UpdatedFrom
+	if updatedFrom == "" && info.Installed != "" {
+		updatedFrom = "new installation"
+	}
+	notesLink := widget.NewHyperlink("Release notes", nil)
+	notesLink.SetURLFromString(getReleaseNotesURL(info.Version))
+
+	labelEntry := widget.NewEntry()
+	labelEntry.SetText(info.Label)
+	labelEntry.SetPlaceHolder("rehearsal, platform A...")
+	notesEntry := widget.NewMultiLineEntry()
+	notesEntry.SetText(info.Notes)
+	notesEntry.SetPlaceHolder("what this installation is used for, what was changed...")
+	notesEntry.Wrapping = fyne.TextWrapWord
+	notesEntry.SetMinRowsVisible(2)
+	saveButton := widget.NewButton("Save", func() {
+		// reread, a launch may have been recorded since the pane was shown
+		current := readInstanceInfo(name)
+		current.Label = strings.TrimSpace(labelEntry.Text)
+		current.Notes = strings.TrimSpace(notesEntry.Text)
+		if err := writeInstanceInfo(name, current); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save the notes of %s: %w", name, err), w)
+			return
+		}
+		recomputeVersionList(w)
+		selectInstalledVersion(name)
+	})
+
+	facts := container.NewGridWithColumns(2,
+		widget.NewForm(
+			widget.NewFormItem("Release", container.NewHBox(widget.NewLabel(info.Version), notesLink)),
+			widget.NewFormItem("Channel", valueLabel(info.Channel)),
+			widget.NewFormItem("Installed", valueLabel(formatRecordedTime(info.Installed))),
+			widget.NewFormItem("Updated from", valueLabel(updatedFrom)),
+			widget.NewFormItem("Size", sizeLabel),
+		),
+		widget.NewForm(
+			widget.NewFormItem("Source", valueLabel(info.Source)),
+			widget.NewFormItem("SHA-256", valueLabel(checksum)),
+			widget.NewFormItem("Last launched", valueLabel(formatRecordedTime(info.LastLaunched))),
+			widget.NewFormItem("Java", valueLabel(info.Java)),
+			widget.NewFormItem("Label", labelEntry),
+		),
+	)
+	notes := container.NewBorder(nil, nil, widget.NewLabel("Notes"), saveButton, notesEntry)
+	versionDetails.Objects = []fyne.CanvasObject{
+		widget.NewCard("", instanceTitle(name), container.NewVBox(facts, notes)),
+	}
+	versionDetails.Refresh()
+}
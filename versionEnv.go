@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/magiconair/properties"
+)
+
+// effectiveEnvironment merges the layers used to launch a version, in a workspace if one is given, and returns
+// the sorted keys, the resulting values and, for each key, the name of the layer that provided the value
+func effectiveEnvironment(version, workspace, profile string) ([]string, map[string]string, map[string]string) {
+	values := map[string]string{}
+	sources := map[string]string{}
+	for _, layer := range getEnvLayers(version, workspace, profile) {
+		props := properties.NewProperties()
+		if err := mergeEnvFile(props, layer.Path); err != nil {
+			log.Printf("Failed to load %s: %v\n", layer.Name, err)
+			continue
+		}
+		for _, key := range props.Keys() {
+			values[key], _ = props.Get(key)
+			sources[key] = layer.Name
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, values, sources
+}
+
+// showEffectiveEnvironment shows the variables that will be given to owlcms when launching a version, or
+// a workspace when one is given, with a profile
+func showEffectiveEnvironment(version, workspace, profile string, w fyne.Window) {
+	InitEnv()
+	keys, values, sources := effectiveEnvironment(version, workspace, profile)
+
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Variable", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Value", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Defined in", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	for _, key := range keys {
+		value := widget.NewLabel(values[key])
+		value.Truncation = fyne.TextTruncateEllipsis
+		grid.Add(widget.NewLabel(key))
+		grid.Add(value)
+		grid.Add(widget.NewLabel(sources[key]))
+	}
+
+	var envDialog dialog.Dialog
+	launched := version
+	editButton := widget.NewButtonWithIcon(fmt.Sprintf("Edit Overrides for %s", version), theme.DocumentCreateIcon(), func() {
+		envDialog.Hide()
+		editVersionEnv(version, w)
+	})
+	if workspace != "" {
+		launched = fmt.Sprintf("workspace %s with OWLCMS %s", workspace, version)
+		editButton = widget.NewButtonWithIcon(fmt.Sprintf("Edit Overrides for %s", workspace), theme.DocumentCreateIcon(), func() {
+			envDialog.Hide()
+			editWorkspaceEnv(workspace, w)
+		})
+	}
+	profileText := "No profile is selected."
+	if profile != "" {
+		profileText = fmt.Sprintf("Profile %s is selected and applied last.", profile)
+	}
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("Environment used when launching %s. %s", launched, profileText)),
+		container.NewHBox(editButton),
+		nil, nil,
+		container.NewVScroll(grid))
+	envDialog = dialog.NewCustom(fmt.Sprintf("Environment for %s", launched), "Close", content, w)
+	envDialog.Resize(fyne.NewSize(700, 450))
+	envDialog.Show()
+}
+
+// editVersionEnv opens the env.properties of a version directory in the settings editor, creating it if needed
+func editVersionEnv(version string, w fyne.Window) {
+	envFilePath := getVersionEnvFilePath(version)
+	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+		header := fmt.Sprintf("# Variables for version %s only: these are applied over those in the shared env.properties\n", version)
+		if err := os.WriteFile(envFilePath, []byte(header), 0644); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to create %s: %w", envFilePath, err), w)
+			return
+		}
+		log.Printf("Created %s\n", envFilePath)
+	}
+	showSettingsWindow(envFilePath, fmt.Sprintf("Settings for %s", version))
+}
+
+func createEnvironmentButton(version string, w fyne.Window, buttonContainer *fyne.Container) {
+	envButton := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		showEffectiveEnvironment(version, "", selectedProfile, w)
+	})
+	buttonContainer.Add(container.NewPadded(envButton))
+}
@@ -5,9 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
-	"owlcms-launcher/downloadUtils"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"time"
 
@@ -28,113 +26,82 @@ func getAllInstalledVersions() []string {
 		return nil
 	}
 
-	versionPattern := regexp.MustCompile(`^\d+\.\d+\.\d+(?:-(?:rc|alpha|beta)(?:\d+)?)?$`)
-	var versions []*semver.Version
+	// installations are sorted by release, most recent first, then by name
+	type instance struct {
+		name    string
+		version *semver.Version
+	}
+	var instances []instance
 	for _, entry := range entries {
-		if entry.IsDir() && versionPattern.MatchString(entry.Name()) {
-			v, err := semver.NewVersion(entry.Name())
+		if entry.IsDir() && isInstanceDir(entry.Name()) {
+			v, err := semver.NewVersion(instanceVersion(entry.Name()))
 			if err == nil {
-				versions = append(versions, v)
+				instances = append(instances, instance{name: entry.Name(), version: v})
 			}
 		}
 	}
-
-	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	sort.SliceStable(instances, func(i, j int) bool {
+		if !instances[i].version.Equal(instances[j].version) {
+			return instances[i].version.GreaterThan(instances[j].version)
+		}
+		return instances[i].name < instances[j].name
+	})
 
 	var versionStrings []string
-	for _, v := range versions {
-		versionStrings = append(versionStrings, v.String())
+	for _, instance := range instances {
+		versionStrings = append(versionStrings, instance.name)
 	}
 
 	return versionStrings
 }
 
-func findLatestInstalled() string {
-	owlcmsDir := owlcmsInstallDir
-	entries, err := os.ReadDir(owlcmsDir)
-	if err != nil {
-		return ""
-	}
-
-	versionPattern := regexp.MustCompile(`^\d+\.\d+\.\d+(?:-(?:rc|alpha|beta)(?:\d+)?)?$`)
-	var versions []*semver.Version
-	for _, entry := range entries {
-		if entry.IsDir() && versionPattern.MatchString(entry.Name()) {
-			v, err := semver.NewVersion(entry.Name())
-			if err == nil {
-				versions = append(versions, v)
-			}
+// findLatestInstalledRelease returns the most recent release among the installations whose release is accepted
+func findLatestInstalledRelease(accept func(version string) bool) string {
+	var latest *semver.Version
+	for _, name := range getAllInstalledVersions() {
+		version := instanceVersion(name)
+		if !accept(version) {
+			continue
+		}
+		v, err := semver.NewVersion(version)
+		if err == nil && (latest == nil || v.GreaterThan(latest)) {
+			latest = v
 		}
 	}
-
-	if len(versions) == 0 {
+	if latest == nil {
 		return ""
 	}
+	return latest.String()
+}
 
-	sort.Sort(sort.Reverse(semver.Collection(versions)))
-	return versions[0].String()
+func findLatestInstalled() string {
+	return findLatestInstalledRelease(func(string) bool { return true })
 }
 
 func findLatestStableInstalled() string {
-	owlcmsDir := owlcmsInstallDir
-	entries, err := os.ReadDir(owlcmsDir)
-	if err != nil {
-		return ""
-	}
-
-	versionPattern := regexp.MustCompile(`^\d+\.\d+\.\d+$`)
-	var versions []*semver.Version
-	for _, entry := range entries {
-		if entry.IsDir() && versionPattern.MatchString(entry.Name()) {
-			v, err := semver.NewVersion(entry.Name())
-			if err == nil {
-				versions = append(versions, v)
-			}
-		}
-	}
-
-	if len(versions) == 0 {
-		return ""
-	}
-
-	sort.Sort(sort.Reverse(semver.Collection(versions)))
-	return versions[0].String()
+	return findLatestInstalledRelease(func(version string) bool { return !containsPreReleaseTag(version) })
 }
 
 func findLatestPrereleaseInstalled() string {
-	owlcmsDir := owlcmsInstallDir
-	entries, err := os.ReadDir(owlcmsDir)
-	if err != nil {
-		return ""
-	}
-
-	versionPattern := regexp.MustCompile(`^\d+\.\d+\.\d+-(?:rc|alpha|beta)(?:\d+)?$`)
-	var versions []*semver.Version
-	for _, entry := range entries {
-		if entry.IsDir() && versionPattern.MatchString(entry.Name()) {
-			v, err := semver.NewVersion(entry.Name())
-			if err == nil {
-				versions = append(versions, v)
-			}
-		}
-	}
-
-	if len(versions) == 0 {
-		return ""
-	}
-
-	sort.Sort(sort.Reverse(semver.Collection(versions)))
-	return versions[0].String()
+	return findLatestInstalledRelease(containsPreReleaseTag)
 }
 
 func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 	versions := getAllInstalledVersions()
+	// named installations need room for their release and label
+	labelWidth := float32(120)
+	for _, version := range versions {
+		if instanceTitle(version) != version {
+			labelWidth = 220
+		}
+	}
 
 	versionList = widget.NewList(
 		func() int { return len(versions) },
 		func() fyne.CanvasObject {
 			// Template item for the version list. Used to compute sizes
 			label := widget.NewLabelWithStyle("LabelTemplate", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			label.Truncation = fyne.TextTruncateEllipsis
 			launchButton := widget.NewButton("ButtonTemplate", nil)
 			launchButton.Resize(fyne.NewSize(80, 25))
 			launchButton.Importance = widget.HighImportance
@@ -142,7 +109,7 @@ func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 				container.NewPadded(launchButton),
 				layout.NewSpacer(), // Add spacer to push buttons to the left
 			)
-			grid := container.New(layout.NewHBoxLayout(), container.NewGridWrap(fyne.NewSize(120, 25), label), buttonContainer)
+			grid := container.New(layout.NewHBoxLayout(), container.NewGridWrap(fyne.NewSize(labelWidth, 25), label), buttonContainer)
 			return grid
 		},
 		func(index widget.ListItemID, item fyne.CanvasObject) {
@@ -152,7 +119,7 @@ func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 			grid := item.(*fyne.Container)
 
 			label := grid.Objects[0].(*fyne.Container).Objects[0].(*widget.Label)
-			label.SetText(version)
+			label.SetText(instanceTitle(version))
 			label.TextStyle = fyne.TextStyle{Bold: true} // Make the version number bold
 			label.Refresh()
 
@@ -161,12 +128,15 @@ func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 
 			createLaunchButton(w, version, stopButton, buttonContainer)
 			createFilesButton(version, w, buttonContainer)
+			createEnvironmentButton(version, w, buttonContainer)
 			if len(allReleases) > 0 {
 				createUpdateButton(version, w, buttonContainer)
 			}
 			if len(versions) > 1 {
 				createImportButton(versions, version, w, buttonContainer)
 			}
+			createRollbackButton(version, w, buttonContainer)
+			createInstanceMenuButton(version, w, buttonContainer)
 			createRemoveButton(version, w, buttonContainer)
 			buttonContainer.Add(layout.NewSpacer()) // Add spacer to push buttons to the left
 			buttonContainer.Refresh()
@@ -176,6 +146,7 @@ func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 	versionList.OnSelected = func(id widget.ListItemID) {
 		if id < len(versions) {
 			log.Printf("Selected version: %s\n", versions[id])
+			showVersionDetails(versions[id], w)
 		}
 	}
 
@@ -195,6 +166,16 @@ func createVersionList(w fyne.Window, stopButton *widget.Button) *widget.List {
 	return versionList
 }
 
+// selectInstalledVersion selects an installation in the version list, which shows its details
+func selectInstalledVersion(name string) {
+	for i, version := range getAllInstalledVersions() {
+		if version == name {
+			versionList.Select(i)
+			return
+		}
+	}
+}
+
 func createImportButton(versions []string, version string, w fyne.Window, buttonContainer *fyne.Container) {
 	importButton := widget.NewButton("Import Data and Config", nil)
 	importButton.Show()
@@ -219,26 +200,11 @@ func createImportButton(versions []string, version string, w fyne.Window, button
 					return
 				}
 
-				sourceDir := filepath.Join(owlcmsInstallDir, sourceVersion)
-				destDir := filepath.Join(owlcmsInstallDir, version)
-
-				if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+				if _, err := os.Stat(filepath.Join(owlcmsInstallDir, sourceVersion)); os.IsNotExist(err) {
 					dialog.ShowError(fmt.Errorf("source version %s does not exist", sourceVersion), w)
 					return
 				}
-
-				// Copy database files
-				if err := copyFiles(filepath.Join(sourceDir, "database"), filepath.Join(destDir, "database"), true); err != nil {
-					log.Printf("No database files to copy from %s\n", sourceDir)
-				}
-				// Copy local files if they are newer
-				if err := copyFiles(filepath.Join(sourceDir, "local"), filepath.Join(destDir, "local"), false); err != nil {
-					log.Printf("No local files to copy from %s\n", sourceDir)
-					dialog.ShowError(fmt.Errorf("failed to copy local files: %w", err), w)
-					return
-				}
-
-				dialog.ShowInformation("Import Complete", fmt.Sprintf("Successfully imported data and config from version %s to version %s", sourceVersion, version), w)
+				showImportPreview(sourceVersion, version, w)
 			},
 			w)
 	}
@@ -247,6 +213,7 @@ func createImportButton(versions []string, version string, w fyne.Window, button
 
 func createUpdateButton(version string, w fyne.Window, buttonContainer *fyne.Container) {
 	updateButton := widget.NewButton("Update", nil)
+	var testButton *widget.Button
 	var mostRecent string
 	var err error
 
@@ -262,29 +229,38 @@ func createUpdateButton(version string, w fyne.Window, buttonContainer *fyne.Con
 	}
 
 	// Check if the current version is stable or a prerelease
-	if !containsPreReleaseTag(version) {
+	if !containsPreReleaseTag(instanceVersion(version)) {
 		mostRecent, err = getMostRecentStableRelease()
 		if err == nil {
-			adjustUpdateButton(mostRecent, version, updateButton, buttonContainer, w)
+			testButton = adjustUpdateButton(mostRecent, version, updateButton, buttonContainer, w)
 		} else {
 			log.Printf("failed to get most recent stable release: %v", err)
 		}
 	} else {
 		mostRecent, err = getMostRecentPrerelease()
 		if err == nil {
-			adjustUpdateButton(mostRecent, version, updateButton, buttonContainer, w)
+			testButton = adjustUpdateButton(mostRecent, version, updateButton, buttonContainer, w)
 		} else {
 			log.Printf("failed to get most recent prerelease: %v", err)
 		}
 	}
 	buttonContainer.Add(container.NewPadded(updateButton))
+	if testButton != nil {
+		buttonContainer.Add(container.NewPadded(testButton))
+	}
 }
 
 func createRemoveButton(version string, w fyne.Window, buttonContainer *fyne.Container) {
 	removeButton := widget.NewButton("Remove", nil)
 	removeButton.OnTapped = func() {
+		message := fmt.Sprintf("Do you want to remove OWLCMS version %s?", version)
+		for _, ws := range listWorkspaces() {
+			if ws.Version == version {
+				message += fmt.Sprintf("\nWorkspace %s runs this version and will need another one.", ws.Name)
+			}
+		}
 		dialog.ShowConfirm("Confirm Remove",
-			fmt.Sprintf("Do you want to remove OWLCMS version %s?", version),
+			message,
 			func(ok bool) {
 				if !ok {
 					return
@@ -323,6 +299,7 @@ func createFilesButton(version string, w fyne.Window, buttonContainer *fyne.Cont
 }
 
 func createLaunchButton(w fyne.Window, version string, stopButton *widget.Button, buttonContainer *fyne.Container) {
+	profileSelect := createProfileSelect(buttonContainer)
 	launchButton := widget.NewButton("Launch", nil)
 	launchButton.Resize(fyne.NewSize(80, 25))
 	launchButton.Importance = widget.HighImportance
@@ -333,8 +310,14 @@ func createLaunchButton(w fyne.Window, version string, stopButton *widget.Button
 			return
 		}
 
+		if profileSelect != nil && profileSelect.Selected != defaultProfile {
+			selectedProfile = profileSelect.Selected
+		} else {
+			selectedProfile = ""
+		}
+		selectedWorkspace = ""
 		log.Printf("Launching version %s\n", version)
-		if err := checkJava(statusLabel); err != nil {
+		if err := checkJava(requiredJavaVersion(version), statusLabel); err != nil {
 			dialog.ShowError(fmt.Errorf("java check/installation failed: %w", err), w)
 			return
 		}
@@ -347,9 +330,11 @@ func createLaunchButton(w fyne.Window, version string, stopButton *widget.Button
 	buttonContainer.Add(container.NewPadded(launchButton))
 }
 
-func adjustUpdateButton(mostRecent string, version string, updateButton *widget.Button, buttonContainer *fyne.Container, w fyne.Window) {
+// adjustUpdateButton points the update button to mostRecent when it is newer, and then returns a button to
+// test the update first, which goes after the update button
+func adjustUpdateButton(mostRecent string, version string, updateButton *widget.Button, buttonContainer *fyne.Container, w fyne.Window) *widget.Button {
 	compare, err := semver.NewVersion(mostRecent)
-	x, err2 := semver.NewVersion(version)
+	x, err2 := semver.NewVersion(instanceVersion(version))
 	if err == nil && err2 == nil {
 		if compare.GreaterThan(x) {
 			updateButton.SetText(fmt.Sprintf("Update to %s", mostRecent))
@@ -357,48 +342,39 @@ func adjustUpdateButton(mostRecent string, version string, updateButton *widget.
 				updateVersion(version, mostRecent, w)
 			}
 			updateButton.Refresh()
-		} else {
-			buttonContainer.Refresh()
+			return widget.NewButton("Test Update", func() {
+				testUpgrade(version, mostRecent, w)
+			})
 		}
+		buttonContainer.Refresh()
 	} else {
 		log.Printf("failed to compare versions: %v %v", err, err2)
 	}
+	return nil
 }
 
 func updateVersion(existingVersion string, targetVersion string, w fyne.Window) {
-	// Note the timestamp of the current version's top-level directory
-	currentVersionDir := filepath.Join(owlcmsInstallDir, existingVersion)
-	modTime, err := os.Stat(currentVersionDir)
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("failed to get info for current version directory: %w", err), w)
+	if currentProcess != nil && currentVersion == existingVersion {
+		dialog.ShowError(fmt.Errorf("stop OWLCMS %s before updating it", existingVersion), w)
 		return
 	}
-
-	// Move the current version to a temporary directory in the installation area
-	tempDir := filepath.Join(owlcmsInstallDir, "temp")
-	err = os.Rename(currentVersionDir, tempDir)
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("failed to move current version to temporary directory: %w", err), w)
+	// The journal lets the next start finish or undo an update that is interrupted
+	journal := newUpdateJournal(existingVersion, targetVersion, updateStaging)
+	if err := checkUpdateTarget(journal); err != nil {
+		dialog.ShowError(err, w)
 		return
 	}
-	// set the modification time of the directory to modTime
-	err = os.Chtimes(tempDir, modTime.ModTime(), modTime.ModTime())
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("failed to set modification time of temporary directory: %w", err), w)
+
+	if err := checkNoUpdateInProgress(); err != nil {
+		dialog.ShowError(err, w)
 		return
 	}
 
-	// Download and extract the version given by string
-	var urlPrefix string
-	if containsPreReleaseTag(targetVersion) {
-		urlPrefix = "https://github.com/owlcms/owlcms4-prerelease/releases/download"
-	} else {
-		urlPrefix = "https://github.com/owlcms/owlcms4/releases/download"
+	// Back up the data before touching anything
+	if _, err := backupVersion(existingVersion, "update"); err != nil {
+		dialog.ShowError(fmt.Errorf("update cancelled, the current data could not be backed up: %w", err), w)
+		return
 	}
-	fileName := fmt.Sprintf("owlcms_%s.zip", targetVersion)
-	zipURL := fmt.Sprintf("%s/%s/%s", urlPrefix, targetVersion, fileName)
-	zipPath := filepath.Join(owlcmsInstallDir, fileName)
-	extractPath := filepath.Join(owlcmsInstallDir, targetVersion)
 
 	progressDialog := dialog.NewCustom(
 		"Updating OWLCMS",
@@ -409,47 +385,72 @@ func updateVersion(existingVersion string, targetVersion string, w fyne.Window)
 
 	defer progressDialog.Hide()
 
-	err = downloadUtils.DownloadArchive(zipURL, zipPath)
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("download failed: %w", err), w)
+	if err := writeJournal(getJournalPath(), journal); err != nil {
+		dialog.ShowError(fmt.Errorf("update cancelled: %w", err), w)
 		return
 	}
-
-	err = downloadUtils.ExtractZip(zipPath, extractPath)
+	conflicts, err := stageUpdate(journal)
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("extraction failed: %w", err), w)
+		abortUpdate(journal)
+		dialog.ShowError(fmt.Errorf("update cancelled, version %s is unchanged: %w", existingVersion, err), w)
 		return
 	}
-
-	// Copy the database from the temporary directory to the new version
-	err = copyFiles(filepath.Join(tempDir, "database"), filepath.Join(extractPath, "database"), true)
-	if err != nil {
-		log.Printf("No database files to copy from %s\n", tempDir)
-	}
-
-	// Copy files newer than the memorized timestamp from the temporary directory to the new version
-	err = copyFiles(filepath.Join(tempDir, "local"), filepath.Join(extractPath, "local"), false)
-	if err != nil {
-		log.Printf("No local files to copy from %s\n", tempDir)
-		dialog.ShowError(fmt.Errorf("failed to copy local files: %w", err), w)
+	journal.State = updateStaged
+	if err := writeJournal(getJournalPath(), journal); err != nil {
+		abortUpdate(journal)
+		dialog.ShowError(fmt.Errorf("update cancelled, version %s is unchanged: %w", existingVersion, err), w)
 		return
 	}
-
-	// Remove the temporary directory
-	err = os.RemoveAll(tempDir)
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("failed to remove temporary directory: %w", err), w)
+	if err := commitUpdate(journal); err != nil {
+		dialog.ShowError(fmt.Errorf("update interrupted, it will be completed at the next start: %w", err), w)
 		return
 	}
 
-	dialog.ShowInformation("Update Complete", fmt.Sprintf("Successfully updated to version %s", targetVersion), w)
+	dialog.ShowInformation("Update Complete", fmt.Sprintf("Successfully updated to version %s.\nVersion %s is kept and can be brought back with \"Roll Back\".", targetVersion, existingVersion), w)
+	if len(conflicts) > 0 {
+		showMergeConflicts(journal.target(), conflicts)
+	}
 
 	// Recompute the version list
 	recomputeVersionList(w)
 
 	// Recompute the downloadTitle
 	checkForNewerVersion()
+	retentionAfterInstall(journal.target(), w)
+}
 
+// createRollbackButton offers to return to the version replaced by the last update, on the row of the new version
+func createRollbackButton(version string, w fyne.Window, buttonContainer *fyne.Container) {
+	last := lastUpdate()
+	if last == nil || last.target() != version {
+		return
+	}
+	rollbackButton := widget.NewButton(fmt.Sprintf("Roll Back to %s", last.fromDescription()), func() {
+		if currentProcess != nil && currentVersion == version {
+			dialog.ShowError(fmt.Errorf("stop OWLCMS %s before rolling back", version), w)
+			return
+		}
+		removed := version
+		if last.target() == last.From {
+			// updated in place, the installation keeps its name
+			removed = fmt.Sprintf("%s of %s", last.To, version)
+		}
+		dialog.ShowConfirm("Confirm Roll Back",
+			fmt.Sprintf("Return to version %s with its data as it was before the update on %s?\n"+
+				"Version %s is removed; its data is backed up first and can be restored from the Backups window.",
+				last.fromDescription(), last.Started.Format("2006-01-02 15:04"), removed),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := rollbackUpdate(last); err != nil {
+					dialog.ShowError(fmt.Errorf("roll back failed: %w", err), w)
+				}
+				recomputeVersionList(w)
+				checkForNewerVersion()
+			}, w)
+	})
+	buttonContainer.Add(container.NewPadded(rollbackButton))
 }
 
 func filterVersions(versions []string, currentVersion string) []string {
@@ -519,6 +520,7 @@ func recomputeVersionList(w fyne.Window) {
 	// Reinitialize the version list
 	log.Println("Reinitializing version list")
 	versionContainer.Objects = nil // Clear the container
+	versionDetails = container.NewVBox()
 	newVersionList := createVersionList(w, stopButton)
 
 	// Update the scroll container's size
@@ -530,6 +532,7 @@ func recomputeVersionList(w fyne.Window) {
 	if numVersions == 0 {
 		versionLabel.Hide()
 		versionScroll.Hide()
+		versionDetails.Hide()
 		versionContainer.Hide()
 	} else {
 		versionLabel.Show()
@@ -538,6 +541,7 @@ func recomputeVersionList(w fyne.Window) {
 	}
 	versionContainer.Add(versionLabel)
 	versionContainer.Add(versionScroll)
+	versionContainer.Add(versionDetails)
 
 	log.Println("Version list reinitialized")
 }
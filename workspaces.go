@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+// workspaceFileName holds the settings of a workspace other than its variables, such as the program version
+const workspaceFileName = "workspace.properties"
+
+// workspaceKeyPrefix distinguishes workspaces from versions where both are named, as in the backup folders
+const workspaceKeyPrefix = "workspace-"
+
+var (
+	selectedWorkspace string // workspace used by the next launch, "" to use the data of the version
+	activeWorkspace   string // workspace of the running server
+)
+
+// workspace is a competition's database, local overrides and env.properties, kept apart from the program
+// versions and run with one of them
+type workspace struct {
+	Name    string
+	Version string
+}
+
+// getWorkspacesDir returns the folder holding one subfolder per workspace
+func getWorkspacesDir() string {
+	return filepath.Join(owlcmsInstallDir, "workspaces")
+}
+
+// getWorkspaceDir returns the folder holding the data of a workspace
+func getWorkspaceDir(name string) string {
+	return filepath.Join(getWorkspacesDir(), name)
+}
+
+// workspaceKey returns the name under which the data of a workspace is backed up
+func workspaceKey(name string) string {
+	return workspaceKeyPrefix + name
+}
+
+// getDataDir returns the folder holding the database and local files of a version, or of a workspace
+// when given a workspace key
+func getDataDir(key string) string {
+	if name, ok := strings.CutPrefix(key, workspaceKeyPrefix); ok {
+		return getWorkspaceDir(name)
+	}
+	return filepath.Join(owlcmsInstallDir, key)
+}
+
+// runningDataKey returns the version or workspace key whose data the running server uses, "" if none runs
+func runningDataKey() string {
+	if currentProcess == nil {
+		return ""
+	}
+	if activeWorkspace != "" {
+		return workspaceKey(activeWorkspace)
+	}
+	return currentVersion
+}
+
+// listWorkspaces returns the workspaces, sorted by name
+func listWorkspaces() []workspace {
+	entries, err := os.ReadDir(getWorkspacesDir())
+	if err != nil {
+		return nil
+	}
+	var workspaces []workspace
+	for _, entry := range entries {
+		if !entry.IsDir() || !profilePattern.MatchString(entry.Name()) {
+			continue
+		}
+		ws, err := readWorkspace(entry.Name())
+		if err != nil {
+			log.Printf("Ignoring workspace %s: %v\n", entry.Name(), err)
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return strings.ToLower(workspaces[i].Name) < strings.ToLower(workspaces[j].Name)
+	})
+	return workspaces
+}
+
+// readWorkspace loads the settings of a workspace
+func readWorkspace(name string) (workspace, error) {
+	props, err := properties.LoadFile(filepath.Join(getWorkspaceDir(name), workspaceFileName), properties.UTF8)
+	if err != nil {
+		return workspace{}, err
+	}
+	return workspace{Name: name, Version: props.GetString("version", "")}, nil
+}
+
+// writeWorkspace saves the settings of a workspace
+func writeWorkspace(ws workspace) error {
+	props := properties.NewProperties()
+	props.Set("version", ws.Version)
+	path := filepath.Join(getWorkspaceDir(ws.Name), workspaceFileName)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	defer f.Close()
+	_, err = props.Write(f, properties.UTF8)
+	return err
+}
+
+// createWorkspace creates a workspace running version. If from is a version or workspace key, its database,
+// env.properties and modified local files are copied into the new workspace.
+func createWorkspace(name, version, from string) error {
+	dir := getWorkspaceDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("workspace %s already exists", name)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "local"), 0755); err != nil {
+		return fmt.Errorf("creating workspace %s: %w", name, err)
+	}
+	var err error
+	if strings.HasPrefix(from, workspaceKeyPrefix) {
+		// everything in a workspace is competition data or an override
+		err = copyFiles(getDataDir(from), dir, true)
+	} else if from != "" {
+		_, err = copyVersionData(getDataDir(from), dir)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("copying the data of %s: %w", from, err)
+	}
+	if err := writeWorkspace(workspace{Name: name, Version: version}); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	log.Printf("Created workspace %s running %s\n", name, version)
+	return nil
+}
+
+// switchWorkspaceVersion makes a workspace run another installed version. Nothing is copied; the data is
+// backed up first since a newer version may migrate the database in a way older ones cannot read.
+func switchWorkspaceVersion(ws workspace, version string) error {
+	if runningDataKey() == workspaceKey(ws.Name) {
+		return fmt.Errorf("stop workspace %s before changing its version", ws.Name)
+	}
+	if _, err := os.Stat(filepath.Join(owlcmsInstallDir, version, "owlcms.jar")); err != nil {
+		return fmt.Errorf("version %s is not installed", version)
+	}
+	if _, err := backupVersion(workspaceKey(ws.Name), "switch"); err != nil {
+		return fmt.Errorf("the data of workspace %s could not be backed up: %w", ws.Name, err)
+	}
+	ws.Version = version
+	if err := writeWorkspace(ws); err != nil {
+		return err
+	}
+	log.Printf("Workspace %s now runs %s\n", ws.Name, version)
+	return nil
+}
+
+// removeWorkspace deletes a workspace after backing up its data
+func removeWorkspace(name string) error {
+	if runningDataKey() == workspaceKey(name) {
+		return fmt.Errorf("stop workspace %s before removing it", name)
+	}
+	if _, err := backupVersion(workspaceKey(name), "remove"); err != nil {
+		return fmt.Errorf("the data of workspace %s could not be backed up: %w", name, err)
+	}
+	return os.RemoveAll(getWorkspaceDir(name))
+}
+
+// getAllDataKeys returns the installed versions followed by the workspace keys
+func getAllDataKeys() []string {
+	keys := getAllInstalledVersions()
+	for _, ws := range listWorkspaces() {
+		keys = append(keys, workspaceKey(ws.Name))
+	}
+	return keys
+}
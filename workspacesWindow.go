@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// emptyWorkspace is the choice, when creating a workspace, of starting without a database
+const emptyWorkspace = "(empty)"
+
+// showWorkspacesWindow lists the workspaces with the version each one runs, and launches them
+func showWorkspacesWindow() {
+	win := fyne.CurrentApp().NewWindow("Workspaces")
+	rows := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		workspaces := listWorkspaces()
+		if len(workspaces) == 0 {
+			rows.Add(widget.NewLabel("No workspace yet. A workspace keeps the database, local files and settings of a\n" +
+				"competition apart from the program, so that it can move to another version without copying anything."))
+		}
+		for _, ws := range workspaces {
+			rows.Add(createWorkspaceRow(ws, win, refresh))
+		}
+		rows.Refresh()
+	}
+
+	newButton := widget.NewButtonWithIcon("New Workspace", theme.ContentAddIcon(), func() {
+		newWorkspace(win, refresh)
+	})
+	folderButton := widget.NewButton("Open Folder", func() {
+		if err := os.MkdirAll(getWorkspacesDir(), 0755); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if err := openFileExplorer(getWorkspacesDir()); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to open %s: %w", getWorkspacesDir(), err), win)
+		}
+	})
+	refresh()
+	win.SetContent(container.NewBorder(container.NewHBox(newButton, folderButton), nil, nil, nil, container.NewVScroll(rows)))
+	win.Resize(fyne.NewSize(850, 400))
+	win.Show()
+}
+
+// createWorkspaceRow shows a workspace with its version and its actions
+func createWorkspaceRow(ws workspace, win fyne.Window, refresh func()) fyne.CanvasObject {
+	name := widget.NewLabelWithStyle(ws.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	buttons := container.NewHBox()
+	profileSelect := createProfileSelect(buttons)
+
+	versions := getAllInstalledVersions()
+	if ws.Version != "" && !slices.Contains(versions, ws.Version) {
+		versions = append(versions, ws.Version)
+	}
+	versionSelect := widget.NewSelect(versions, nil)
+	versionSelect.SetSelected(ws.Version)
+	versionSelect.OnChanged = func(version string) {
+		if version == ws.Version {
+			return
+		}
+		dialog.ShowConfirm("Change Version",
+			fmt.Sprintf("Run workspace %s with OWLCMS %s instead of %s?\nIts data is backed up first; a database opened by a newer version may not open in an older one.",
+				ws.Name, version, ws.Version),
+			func(ok bool) {
+				if ok {
+					if err := switchWorkspaceVersion(ws, version); err != nil {
+						dialog.ShowError(err, win)
+					}
+				}
+				refresh()
+			}, win)
+	}
+
+	launchButton := widget.NewButton("Launch", nil)
+	launchButton.Importance = widget.HighImportance
+	launchButton.OnTapped = func() {
+		if currentProcess != nil {
+			dialog.ShowError(fmt.Errorf("OWLCMS is already running"), win)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(owlcmsInstallDir, ws.Version, "owlcms.jar")); err != nil {
+			dialog.ShowError(fmt.Errorf("version %s is not installed, choose another version for workspace %s", ws.Version, ws.Name), win)
+			return
+		}
+		if profileSelect != nil && profileSelect.Selected != defaultProfile {
+			selectedProfile = profileSelect.Selected
+		} else {
+			selectedProfile = ""
+		}
+		selectedWorkspace = ws.Name
+		log.Printf("Launching workspace %s with version %s\n", ws.Name, ws.Version)
+		if err := checkJava(requiredJavaVersion(ws.Version), statusLabel); err != nil {
+			dialog.ShowError(fmt.Errorf("java check/installation failed: %w", err), win)
+			return
+		}
+		if err := launchOwlcms(ws.Version, launchButton, stopButton); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		win.Close()
+	}
+
+	filesButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		if err := openFileExplorer(getWorkspaceDir(ws.Name)); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to open %s: %w", getWorkspaceDir(ws.Name), err), win)
+		}
+	})
+	settingsButton := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		profile := ""
+		if profileSelect != nil && profileSelect.Selected != defaultProfile {
+			profile = profileSelect.Selected
+		}
+		showEffectiveEnvironment(ws.Version, ws.Name, profile, win)
+	})
+	removeButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		dialog.ShowConfirm("Remove Workspace",
+			fmt.Sprintf("Remove workspace %s? Its data is backed up first and can be restored from the Backups window.", ws.Name),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := removeWorkspace(ws.Name); err != nil {
+					dialog.ShowError(err, win)
+				}
+				refresh()
+			}, win)
+	})
+
+	buttons.Add(launchButton)
+	buttons.Add(filesButton)
+	buttons.Add(settingsButton)
+	buttons.Add(removeButton)
+	left := container.NewHBox(container.NewGridWrap(fyne.NewSize(180, 36), name), widget.NewLabel("runs"), versionSelect)
+	return container.NewBorder(nil, nil, left, buttons)
+}
+
+// editWorkspaceEnv opens the env.properties of a workspace in the settings editor, creating it if needed
+func editWorkspaceEnv(name string, w fyne.Window) {
+	envFilePath := filepath.Join(getWorkspaceDir(name), "env.properties")
+	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+		header := fmt.Sprintf("# Variables for workspace %s: these are applied over those of env.properties and of the version\n", name)
+		if err := os.WriteFile(envFilePath, []byte(header), 0644); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to create %s: %w", envFilePath, err), w)
+			return
+		}
+		log.Printf("Created %s\n", envFilePath)
+	}
+	showSettingsWindow(envFilePath, fmt.Sprintf("Settings for workspace %s", name))
+}
+
+// newWorkspace asks for the name, version and initial data of a new workspace
+func newWorkspace(win fyne.Window, refresh func()) {
+	versions := getAllInstalledVersions()
+	if len(versions) == 0 {
+		dialog.ShowInformation("New Workspace", "Install a version first.", win)
+		return
+	}
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Nationals 2026")
+	nameEntry.Validator = func(name string) error {
+		if !profilePattern.MatchString(strings.TrimSpace(name)) {
+			return fmt.Errorf("use letters, digits, spaces, - and _")
+		}
+		return nil
+	}
+	versionSelect := widget.NewSelect(versions, nil)
+	versionSelect.SetSelected(versions[0])
+	fromSelect := widget.NewSelect(append([]string{emptyWorkspace}, getAllDataKeys()...), nil)
+	fromSelect.SetSelected(emptyWorkspace)
+
+	dialog.ShowForm("New Workspace", "Create", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Name", nameEntry),
+			widget.NewFormItem("Version", versionSelect),
+			widget.NewFormItem("Start with the data of", fromSelect),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			from := fromSelect.Selected
+			if from == emptyWorkspace {
+				from = ""
+			}
+			if from != "" && runningDataKey() == from {
+				dialog.ShowError(fmt.Errorf("stop OWLCMS before copying the data of %s, its database is in use", from), win)
+				return
+			}
+			if err := createWorkspace(strings.TrimSpace(nameEntry.Text), versionSelect.Selected, from); err != nil {
+				dialog.ShowError(err, win)
+			}
+			refresh()
+		}, win)
+}